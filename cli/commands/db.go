@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/spf13/cobra"
+)
+
+type DatabaseCommandOptions struct {
+	// Returns the database the commands operate on. It is invoked when a subcommand runs.
+	Database func(cmd *cobra.Command) (sqldb.SqlBackend, error)
+
+	// The database engine, which determines how DSN is parsed.
+	Adapter sqldb.SQLAdapter
+
+	// Data source name the database was opened with. Optional; its redacted form is shown when verbose logging is on.
+	DSN string
+}
+
+// NewDatabaseCommand - returns the `db` command tree with the subcommand status.
+//
+// `db status` prints the report of sqldb.Health and exits with an error if the database is unhealthy.
+//
+// Usage:
+//
+//	state := cli.NewCommandState(cli.CommandFlags{})
+//	rootCmd.AddCommand(commands.NewDatabaseCommand(state, commands.DatabaseCommandOptions{
+//		Database: func(cmd *cobra.Command) (sqldb.SqlBackend, error) { return db, nil },
+//		Adapter:  sqldb.PostgreSQL,
+//		DSN:      os.Getenv("DATABASE_URL"),
+//	}))
+func NewDatabaseCommand(state *cli.CommandState, options DatabaseCommandOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Inspect the database connection",
+	}
+
+	cmd.AddCommand(newDatabaseStatusCommand(state, options))
+
+	return cmd
+}
+
+func (options DatabaseCommandOptions) database(cmd *cobra.Command) (sqldb.SqlBackend, error) {
+	if options.Database == nil {
+		return nil, fmt.Errorf("no database configured")
+	}
+
+	return options.Database(cmd)
+}
+
+func newDatabaseStatusCommand(state *cli.CommandState, options DatabaseCommandOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Report database health, latency and connection pool statistics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := options.database(cmd)
+			if err != nil {
+				return err
+			}
+
+			report, healthErr := sqldb.Health(cmd.Context(), db)
+
+			if state.Flags.VerboseLogging && options.DSN != "" {
+				if dsn, err := sqldb.ParseDSN(options.Adapter, options.DSN); err == nil {
+					report.DSN = dsn.Redacted()
+				}
+			}
+
+			if err := render(state, cmd, report); err != nil {
+				return err
+			}
+
+			// An unhealthy database is a check failure rather than a usage error.
+			cmd.SilenceUsage = true
+
+			return healthErr
+		},
+	}
+}
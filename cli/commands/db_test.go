@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/spf13/cobra"
+)
+
+func TestNewDatabaseCommand_withoutDatabase(t *testing.T) {
+	_, err := execute(t, func(state *cli.CommandState) *cobra.Command {
+		return NewDatabaseCommand(state, DatabaseCommandOptions{})
+	}, "db", "status")
+
+	if err == nil || !strings.Contains(err.Error(), "no database configured") {
+		t.Errorf("Execute() error = %v, want a missing database error", err)
+	}
+}
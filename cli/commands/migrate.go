@@ -0,0 +1,284 @@
+// Package commands provides ready-made cobra commands built on cli.CommandState.
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/oleoneto/go-toolkit/sqldb/migrate"
+	"github.com/spf13/cobra"
+)
+
+type MigrateCommandOptions struct {
+	// Returns the migrator the commands operate on.
+	// It is invoked when a subcommand runs, so the database can be opened in a PersistentPreRun hook.
+	// Subcommands other than `create` fail if it is nil.
+	Migrator func(cmd *cobra.Command) (*migrate.Migrator, error)
+
+	// Directory where `migrate create` writes new migration files.
+	Directory string
+}
+
+// NewMigrateCommand - returns the `migrate` command tree with the subcommands up, down, redo, status and create.
+//
+// Results are printed through the state's Writer, so they honor the `--output` flag if the root command defines one.
+//
+// Usage:
+//
+//	state := cli.NewCommandState(cli.CommandFlags{})
+//	rootCmd.AddCommand(commands.NewMigrateCommand(state, commands.MigrateCommandOptions{
+//		Migrator: func(cmd *cobra.Command) (*migrate.Migrator, error) {
+//			return migrate.New(db, os.DirFS("db/migrations"), migrate.Options{Adapter: sqldb.PostgreSQL})
+//		},
+//		Directory: "db/migrations",
+//	}))
+func NewMigrateCommand(state *cli.CommandState, options MigrateCommandOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database migrations",
+	}
+
+	cmd.AddCommand(
+		newMigrateUpCommand(state, options),
+		newMigrateDownCommand(state, options),
+		newMigrateRedoCommand(state, options),
+		newMigrateStatusCommand(state, options),
+		newMigrateCreateCommand(state, options),
+	)
+
+	return cmd
+}
+
+func newMigrateUpCommand(state *cli.CommandState, options MigrateCommandOptions) *cobra.Command {
+	var steps int
+
+	cmd := &cobra.Command{
+		Use:   "up [version|name]",
+		Short: "Apply pending migrations, optionally up to and including the given one",
+		Args:  migrationFilterArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := options.migrator(cmd)
+			if err != nil {
+				return err
+			}
+
+			var results []migrate.Result
+			if len(args) == 1 {
+				target, _ := cli.ParseVersionArgs(args[0])
+				results, err = m.UpTo(cmd.Context(), target)
+			} else {
+				results, err = m.Up(cmd.Context(), steps)
+			}
+
+			return printResults(state, cmd, results, err)
+		},
+	}
+
+	cmd.Flags().IntVar(&steps, "steps", 0, "number of migrations to apply (0 applies all)")
+
+	return cmd
+}
+
+func newMigrateDownCommand(state *cli.CommandState, options MigrateCommandOptions) *cobra.Command {
+	var steps int
+
+	cmd := &cobra.Command{
+		Use:   "down [version|name]",
+		Short: "Revert applied migrations, optionally down to and including the given one",
+		Args:  migrationFilterArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := options.migrator(cmd)
+			if err != nil {
+				return err
+			}
+
+			var results []migrate.Result
+			if len(args) == 1 {
+				target, _ := cli.ParseVersionArgs(args[0])
+				results, err = m.DownTo(cmd.Context(), target)
+			} else {
+				results, err = m.Down(cmd.Context(), steps)
+			}
+
+			return printResults(state, cmd, results, err)
+		},
+	}
+
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to revert (0 reverts all)")
+
+	return cmd
+}
+
+func newMigrateRedoCommand(state *cli.CommandState, options MigrateCommandOptions) *cobra.Command {
+	var steps int
+
+	cmd := &cobra.Command{
+		Use:   "redo",
+		Short: "Revert and re-apply the latest migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := options.migrator(cmd)
+			if err != nil {
+				return err
+			}
+
+			results, err := m.Redo(cmd.Context(), steps)
+
+			return printResults(state, cmd, results, err)
+		},
+	}
+
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to redo (0 redoes all)")
+
+	return cmd
+}
+
+func newMigrateStatusCommand(state *cli.CommandState, options MigrateCommandOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and whether they have been applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := options.migrator(cmd)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := m.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			return render(state, cmd, migrationStatuses(statuses))
+		},
+	}
+}
+
+func newMigrateCreateCommand(state *cli.CommandState, options MigrateCommandOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create empty up and down scripts for a new migration",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.Directory == "" {
+				return fmt.Errorf("no migrations directory configured")
+			}
+
+			migration, err := migrate.Create(options.Directory, args[0])
+			if err != nil {
+				return err
+			}
+
+			return render(state, cmd, migrationStatuses{{Migration: migration}})
+		},
+	}
+}
+
+// migrator - returns the migrator of the options, or an error if none was configured.
+func (options MigrateCommandOptions) migrator(cmd *cobra.Command) (*migrate.Migrator, error) {
+	if options.Migrator == nil {
+		return nil, fmt.Errorf("no migrator configured")
+	}
+
+	return options.Migrator(cmd)
+}
+
+// migrationFilterArgs - accepts at most one argument, which must be a valid migration version or name.
+func migrationFilterArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		if _, err := cli.ParseVersionArgs(args[0]); err != nil {
+			return fmt.Errorf("%s: %s", err, args[0])
+		}
+	}
+
+	return nil
+}
+
+// printResults - prints the migrations that ran before returning the error that interrupted them, if any.
+func printResults(state *cli.CommandState, cmd *cobra.Command, results []migrate.Result, err error) error {
+	if perr := render(state, cmd, migrationResults(results)); perr != nil {
+		return perr
+	}
+
+	return err
+}
+
+// render - writes data using the formatter selected by the `--output` flag.
+func render(state *cli.CommandState, cmd *cobra.Command, data any) error {
+	if cmd.Flag("output") != nil {
+		state.SetFormatter(cmd, nil)
+	}
+
+	return state.Writer.Print(data)
+}
+
+type (
+	migrationResults  []migrate.Result
+	migrationStatuses []migrate.MigrationStatus
+)
+
+func (r migrationResults) TableWriter() table.Writer {
+	t := cli.Initialize(cli.TableOptions{Header: table.Row{"Direction", "Version", "Name", "Duration"}})
+
+	for _, result := range r {
+		t.AppendRow(table.Row{result.Direction, result.Version, result.Name, result.Duration})
+	}
+
+	return t
+}
+
+func (r migrationResults) String() string {
+	lines := make([]string, 0, len(r))
+
+	for _, result := range r {
+		lines = append(lines, fmt.Sprintf("%-4s %s_%s (%s)", result.Direction, result.Version, result.Name, result.Duration))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (s migrationStatuses) TableWriter() table.Writer {
+	t := cli.Initialize(cli.TableOptions{Header: table.Row{"Status", "Version", "Name", "Applied At"}})
+
+	for _, status := range s {
+		t.AppendRow(table.Row{statusLabel(status), status.Version, status.Name, statusAppliedAt(status)})
+	}
+
+	return t
+}
+
+func (s migrationStatuses) String() string {
+	lines := make([]string, 0, len(s))
+
+	for _, status := range s {
+		lines = append(lines, fmt.Sprintf("%-7s %s_%s", statusLabel(status), status.Version, status.Name))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func statusLabel(status migrate.MigrationStatus) string {
+	switch {
+	case status.Missing:
+		return "missing"
+	case status.Applied:
+		return "up"
+	}
+
+	return "down"
+}
+
+func statusAppliedAt(status migrate.MigrationStatus) string {
+	if status.AppliedAt == nil {
+		return ""
+	}
+
+	return status.AppliedAt.Format(time.RFC3339)
+}
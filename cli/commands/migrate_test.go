@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewstinnett/gout/v2"
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/migrate"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+	"github.com/spf13/cobra"
+)
+
+var testMigrations = []migrate.Migration{
+	{Version: "20240101000000000000", Name: "create_a", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a"},
+	{Version: "20240102000000000000", Name: "create_b", Up: "CREATE TABLE b (id INT)", Down: "DROP TABLE b"},
+}
+
+// execute - runs the command under a root command defining the `--output` flag and returns what it printed.
+func execute(t *testing.T, cmd func(state *cli.CommandState) *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+
+	var output bytes.Buffer
+
+	state := &cli.CommandState{Writer: gout.New(gout.WithWriter(&output))}
+
+	root := &cobra.Command{Use: "app", SilenceErrors: true, SilenceUsage: true}
+	root.PersistentFlags().Var(&cli.FlagEnum{Allowed: []string{"plain", "json", "table"}, Default: "plain"}, "output", "output format")
+	root.AddCommand(cmd(state))
+	root.SetArgs(args)
+
+	err := root.ExecuteContext(context.Background())
+
+	return output.String(), err
+}
+
+// expectApplied - scripts the lookup of the applied migrations.
+func expectApplied(db *sqltest.Backend, migrations ...migrate.Migration) {
+	rows := [][]any{}
+	for _, m := range migrations {
+		rows = append(rows, []any{m.Version, m.Name, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)})
+	}
+
+	db.ExpectExec(`^CREATE TABLE IF NOT EXISTS schema_migrations `)
+	db.ExpectQuery(`^SELECT version, name, applied_at FROM schema_migrations$`).WillReturnRows([]string{"version", "name", "applied_at"}, rows...)
+}
+
+func TestNewMigrateCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		expect  func(db *sqltest.Backend)
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "up",
+			args: []string{"migrate", "up", "--steps", "1"},
+			expect: func(db *sqltest.Backend) {
+				expectApplied(db)
+				db.ExpectExec(`^CREATE TABLE a`)
+				db.ExpectExec(`^INSERT INTO schema_migrations`).WithArgs("20240101000000000000", "create_a", sqltest.AnyArg)
+			},
+			want: []string{"up   20240101000000000000_create_a"},
+		},
+		{
+			name: "down reverts one migration by default",
+			args: []string{"migrate", "down"},
+			expect: func(db *sqltest.Backend) {
+				expectApplied(db, testMigrations...)
+				db.ExpectExec(`^DROP TABLE b`)
+				db.ExpectExec(`^DELETE FROM schema_migrations`).WithArgs("20240102000000000000")
+			},
+			want: []string{"down 20240102000000000000_create_b"},
+		},
+		{
+			name: "down to a version",
+			args: []string{"migrate", "down", "create_a"},
+			expect: func(db *sqltest.Backend) {
+				expectApplied(db, testMigrations...)
+				db.ExpectExec(`^DROP TABLE b`)
+				db.ExpectExec(`^DELETE FROM schema_migrations`).WithArgs("20240102000000000000")
+				db.ExpectExec(`^DROP TABLE a`)
+				db.ExpectExec(`^DELETE FROM schema_migrations`).WithArgs("20240101000000000000")
+			},
+			want: []string{"down 20240102000000000000_create_b", "down 20240101000000000000_create_a"},
+		},
+		{
+			name:   "status as plain text",
+			args:   []string{"migrate", "status"},
+			expect: func(db *sqltest.Backend) { expectApplied(db, testMigrations[0]) },
+			want:   []string{"up      20240101000000000000_create_a", "down    20240102000000000000_create_b"},
+		},
+		{
+			name:   "status as JSON",
+			args:   []string{"migrate", "status", "--output", "json"},
+			expect: func(db *sqltest.Backend) { expectApplied(db, testMigrations[0]) },
+			want:   []string{`"version":"20240101000000000000"`, `"applied":true`, `"applied_at":"2024-01-01T12:00:00Z"`},
+		},
+		{
+			name:   "status as table",
+			args:   []string{"migrate", "status", "--output", "table"},
+			expect: func(db *sqltest.Backend) { expectApplied(db, testMigrations[0]) },
+			want:   []string{"STATUS", "APPLIED AT", "create_b"},
+		},
+		{name: "invalid target", args: []string{"migrate", "up", "create-a"}, wantErr: true},
+		{name: "too many arguments", args: []string{"migrate", "redo", "create_a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			if tt.expect != nil {
+				tt.expect(db)
+			}
+
+			output, err := execute(t, func(state *cli.CommandState) *cobra.Command {
+				return NewMigrateCommand(state, MigrateCommandOptions{
+					Migrator: func(cmd *cobra.Command) (*migrate.Migrator, error) {
+						return migrate.NewWithMigrations(db, testMigrations, migrate.Options{Adapter: sqldb.SQLite3})
+					},
+				})
+			}, tt.args...)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Execute() printed %q, want %q", output, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMigrateCommand_flags(t *testing.T) {
+	cmd := NewMigrateCommand(&cli.CommandState{}, MigrateCommandOptions{})
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{command: "up", want: "0"},
+		{command: "down", want: "1"},
+		{command: "redo", want: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			sub, _, err := cmd.Find([]string{tt.command})
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+
+			if got := sub.Flags().Lookup("steps").DefValue; got != tt.want {
+				t.Errorf("--steps default = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMigrateCommand_withoutMigrator(t *testing.T) {
+	_, err := execute(t, func(state *cli.CommandState) *cobra.Command {
+		return NewMigrateCommand(state, MigrateCommandOptions{})
+	}, "migrate", "status")
+
+	if err == nil || !strings.Contains(err.Error(), "no migrator configured") {
+		t.Errorf("Execute() error = %v, want a missing migrator error", err)
+	}
+}
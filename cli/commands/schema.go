@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/spf13/cobra"
+)
+
+type SchemaCommandOptions struct {
+	// Returns the database the commands inspect. It is invoked when a subcommand runs.
+	Database func(cmd *cobra.Command) (sqldb.SqlBackend, error)
+
+	Adapter sqldb.SQLAdapter
+
+	// Path of the checked-in schema snapshot. Its extension (.sql, .yaml or .yml) selects the dump format.
+	Snapshot string
+}
+
+// NewSchemaCommand - returns the `schema` command tree with the subcommands show, dump and check.
+//
+// `schema check` exits with an error describing the differences whenever the database
+// no longer matches the snapshot written by `schema dump`.
+//
+// Usage:
+//
+//	state := cli.NewCommandState(cli.CommandFlags{})
+//	rootCmd.AddCommand(commands.NewSchemaCommand(state, commands.SchemaCommandOptions{
+//		Database: func(cmd *cobra.Command) (sqldb.SqlBackend, error) { return db, nil },
+//		Adapter:  sqldb.PostgreSQL,
+//		Snapshot: "db/schema.sql",
+//	}))
+func NewSchemaCommand(state *cli.CommandState, options SchemaCommandOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Inspect the database schema and detect drift from its snapshot",
+	}
+
+	cmd.AddCommand(
+		newSchemaShowCommand(state, options),
+		newSchemaDumpCommand(options),
+		newSchemaCheckCommand(options),
+	)
+
+	return cmd
+}
+
+func newSchemaShowCommand(state *cli.CommandState, options SchemaCommandOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "List the columns of every table",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := inspectSchema(cmd, options)
+			if err != nil {
+				return err
+			}
+
+			return render(state, cmd, schema)
+		},
+	}
+}
+
+func newSchemaDumpCommand(options SchemaCommandOptions) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Write the current schema to the snapshot file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dump, err := dumpSchema(cmd, options, file)
+			if err != nil {
+				return err
+			}
+
+			// The dump is already in the snapshot format, so --output does not apply.
+			if file == "-" {
+				_, err = cmd.OutOrStdout().Write(dump)
+				return err
+			}
+
+			return os.WriteFile(file, dump, 0o644)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", options.Snapshot, `snapshot file (use "-" to write to stdout)`)
+
+	return cmd
+}
+
+func newSchemaCheckCommand(options SchemaCommandOptions) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Compare the current schema against the snapshot file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			dump, err := dumpSchema(cmd, options, file)
+			if err != nil {
+				return err
+			}
+
+			// Drift is a check failure rather than a usage error.
+			cmd.SilenceUsage = true
+
+			return sqldb.CompareSchemaDump(snapshot, dump)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", options.Snapshot, "snapshot file")
+
+	return cmd
+}
+
+func (options SchemaCommandOptions) database(cmd *cobra.Command) (sqldb.SqlBackend, error) {
+	if options.Database == nil {
+		return nil, fmt.Errorf("no database configured")
+	}
+
+	return options.Database(cmd)
+}
+
+func inspectSchema(cmd *cobra.Command, options SchemaCommandOptions) (*sqldb.Schema, error) {
+	db, err := options.database(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqldb.Inspect(cmd.Context(), db, options.Adapter)
+}
+
+// dumpSchema - renders the current schema in the format matching the snapshot file.
+// Stdout dumps use the format of the configured snapshot, falling back to SQL.
+func dumpSchema(cmd *cobra.Command, options SchemaCommandOptions, file string) ([]byte, error) {
+	if file == "" {
+		return nil, fmt.Errorf("no schema snapshot file configured")
+	}
+
+	if file == "-" {
+		file = options.Snapshot
+	}
+
+	format := sqldb.SCHEMA_FORMAT_SQL
+	if file != "" {
+		var err error
+		if format, err = sqldb.SchemaFormatOf(file); err != nil {
+			return nil, err
+		}
+	}
+
+	schema, err := inspectSchema(cmd, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Dump(format)
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/spf13/cobra"
+)
+
+func TestNewSchemaCommand_withoutDatabase(t *testing.T) {
+	snapshot := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(snapshot, []byte{}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "show", args: []string{"schema", "show"}},
+		{name: "dump", args: []string{"schema", "dump", "--file", "-"}},
+		{name: "check", args: []string{"schema", "check"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execute(t, func(state *cli.CommandState) *cobra.Command {
+				return NewSchemaCommand(state, SchemaCommandOptions{Snapshot: snapshot})
+			}, tt.args...)
+
+			if err == nil || !strings.Contains(err.Error(), "no database configured") {
+				t.Errorf("Execute() error = %v, want a missing database error", err)
+			}
+		})
+	}
+}
@@ -6,6 +6,15 @@ type TableFormattable interface{ TableWriter() table.Writer }
 
 type TableFormatter struct{}
 
+// NewTableWriter - returns a table with the given header, in the style shared by all TableFormattable types.
+func NewTableWriter(header table.Row) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(header)
+
+	return t
+}
+
 func (f *TableFormatter) Format(data any) ([]byte, error) {
 	tw, ok := data.(TableFormattable)
 	if !ok {
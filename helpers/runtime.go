@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"fmt"
 	"runtime"
 	"strings"
 )
@@ -11,3 +12,39 @@ func FuncName() string {
 	strs := strings.Split((runtime.FuncForPC(pc).Name()), "/")
 	return strs[len(strs)-1]
 }
+
+// CallerOutside - returns the name and location of the closest function in the call stack that does not
+// belong to any of the given packages, formatted like FuncName. It returns empty strings if there is none.
+//
+// Usage:
+//
+//	name, location := CallerOutside("database/sql", "github.com/oleoneto/go-toolkit/sqldb")
+//	// main.(*Repository).FindUser, /app/repository.go:42
+func CallerOutside(packages ...string) (name string, location string) {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+
+		if frame.Function != "" && !inPackages(frame.Function, packages) {
+			strs := strings.Split(frame.Function, "/")
+			return strs[len(strs)-1], fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return "", ""
+		}
+	}
+}
+
+// inPackages - returns true if the fully qualified function name belongs to one of the packages or their subpackages.
+func inPackages(function string, packages []string) bool {
+	for _, pkg := range packages {
+		if rest, ok := strings.CutPrefix(function, pkg); ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCallerOutside(t *testing.T) {
+	name, location := CallerOutside()
+	if name != "helpers.TestCallerOutside" || !strings.Contains(location, "runtime_test.go:") {
+		t.Errorf("CallerOutside() = %v, %v", name, location)
+	}
+
+	name, _ = CallerOutside("github.com/oleoneto/go-toolkit/helpers")
+	if name != "testing.tRunner" {
+		t.Errorf("CallerOutside() = %v, want testing.tRunner", name)
+	}
+}
+
+func Test_inPackages(t *testing.T) {
+	tests := []struct {
+		function string
+		want     bool
+	}{
+		{function: "github.com/oleoneto/go-toolkit/sqldb.QueryAll[...]", want: true},
+		{function: "github.com/oleoneto/go-toolkit/sqldb/query.(*SelectQuery).Build", want: true},
+		{function: "github.com/oleoneto/go-toolkit/sqldbx.Open", want: false},
+		{function: "main.main", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			if got := inPackages(tt.function, []string{"github.com/oleoneto/go-toolkit/sqldb"}); got != tt.want {
+				t.Errorf("inPackages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,24 @@
 package httpclient
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT                 = 30 * time.Second
+	DEFAULT_DIAL_TIMEOUT            = 10 * time.Second
+	DEFAULT_KEEP_ALIVE              = 30 * time.Second
+	DEFAULT_TLS_HANDSHAKE_TIMEOUT   = 10 * time.Second
+	DEFAULT_RESPONSE_HEADER_TIMEOUT = 20 * time.Second
+	DEFAULT_IDLE_CONN_TIMEOUT       = 90 * time.Second
+	DEFAULT_MAX_IDLE_CONNS          = 100
+	DEFAULT_MAX_IDLE_CONNS_PER_HOST = 10
 )
 
 var (
@@ -11,22 +27,227 @@ var (
 	clientOnce sync.Once
 )
 
-// Example usage:
-//
-//	 url, _ := url.Parse("http://google.com/search?q=golang")
+type (
+	// Option - configures a client built by NewClient.
+	Option func(*config) error
+
+	config struct {
+		timeout               time.Duration
+		dialTimeout           time.Duration
+		tlsHandshakeTimeout   time.Duration
+		responseHeaderTimeout time.Duration
+		idleConnTimeout       time.Duration
+		maxIdleConns          int
+		maxIdleConnsPerHost   int
+		maxConnsPerHost       int
+		proxy                 func(*http.Request) (*url.URL, error)
+		tlsConfig             *tls.Config
+		baseURL               *url.URL
+		transport             http.RoundTripper
+		retry                 *RetryPolicy
+		middlewares           []Middleware
+	}
+
+	// baseURLTransport - resolves relative request URLs against a base URL.
+	baseURLTransport struct {
+		base *url.URL
+		next http.RoundTripper
+	}
+)
+
+// New - returns the client shared by the whole process, built by NewClient with its default settings.
 //
-//	 request := http.Request{
-//		  Method: "GET"
-//		  URL:    url,
-//		  Header: map[string][]string{"User-Agent": "curl"},
-//		  Body: &RequestBody{Data: []byte()},
-//	 }
+// Usage:
 //
-//	 response, err := HTTPClient.Do(&request)
+//	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/search?q=golang", nil)
+//	response, err := httpclient.New().Do(request)
 func New() *http.Client {
 	clientOnce.Do(func() {
-		client = &http.Client{}
+		client, _ = NewClient()
 	})
 
 	return client
 }
+
+// NewClient - returns a new client configured by the options.
+//
+// Unless overridden, requests time out after DEFAULT_TIMEOUT, connections are dialed with DEFAULT_DIAL_TIMEOUT,
+// and proxies are read from the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+//
+// Usage:
+//
+//	client, err := httpclient.NewClient(
+//		httpclient.WithBaseURL("https://api.example.com/v1/"),
+//		httpclient.WithTimeout(5*time.Second),
+//		httpclient.WithMaxConnsPerHost(20),
+//	)
+//
+//	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "users/42", nil)
+//	response, err := client.Do(request) // GET https://api.example.com/v1/users/42
+func NewClient(options ...Option) (*http.Client, error) {
+	c := &config{
+		timeout:               DEFAULT_TIMEOUT,
+		dialTimeout:           DEFAULT_DIAL_TIMEOUT,
+		tlsHandshakeTimeout:   DEFAULT_TLS_HANDSHAKE_TIMEOUT,
+		responseHeaderTimeout: DEFAULT_RESPONSE_HEADER_TIMEOUT,
+		idleConnTimeout:       DEFAULT_IDLE_CONN_TIMEOUT,
+		maxIdleConns:          DEFAULT_MAX_IDLE_CONNS,
+		maxIdleConnsPerHost:   DEFAULT_MAX_IDLE_CONNS_PER_HOST,
+		proxy:                 http.ProxyFromEnvironment,
+	}
+
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+
+	transport := c.transport
+	if transport == nil {
+		transport = c.newTransport()
+	}
+
+	if c.retry != nil {
+		transport = NewRetryTransport(transport, *c.retry)
+	}
+
+	transport = Chain(transport, c.middlewares...)
+
+	if c.baseURL != nil {
+		transport = &baseURLTransport{base: c.baseURL, next: transport}
+	}
+
+	return &http.Client{Transport: transport, Timeout: c.timeout}, nil
+}
+
+// WithTimeout - limits the time a request may take, including redirects and reading the response body.
+// Zero means no limit.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) error { return setDuration(&c.timeout, "timeout", d) }
+}
+
+// WithDialTimeout - limits the time spent establishing a TCP connection.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *config) error { return setDuration(&c.dialTimeout, "dial timeout", d) }
+}
+
+// WithTLSHandshakeTimeout - limits the time spent on TLS handshakes.
+func WithTLSHandshakeTimeout(d time.Duration) Option {
+	return func(c *config) error { return setDuration(&c.tlsHandshakeTimeout, "TLS handshake timeout", d) }
+}
+
+// WithResponseHeaderTimeout - limits the time spent waiting for response headers once the request was written.
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(c *config) error { return setDuration(&c.responseHeaderTimeout, "response header timeout", d) }
+}
+
+// WithIdleConnTimeout - sets how long idle connections are kept in the pool.
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(c *config) error { return setDuration(&c.idleConnTimeout, "idle connection timeout", d) }
+}
+
+// WithMaxIdleConns - sets how many idle connections are kept in the pool, in total and per host.
+func WithMaxIdleConns(total, perHost int) Option {
+	return func(c *config) error {
+		if total < 0 || perHost < 0 {
+			return fmt.Errorf("invalid idle connection limits: %d, %d", total, perHost)
+		}
+
+		c.maxIdleConns, c.maxIdleConnsPerHost = total, perHost
+		return nil
+	}
+}
+
+// WithMaxConnsPerHost - limits the number of connections per host, including those in use. Zero means no limit.
+func WithMaxConnsPerHost(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return fmt.Errorf("invalid connection limit: %d", n)
+		}
+
+		c.maxConnsPerHost = n
+		return nil
+	}
+}
+
+// WithProxy - sets the function selecting the proxy of each request, i.e. http.ProxyURL(u). Nil disables proxies.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *config) error {
+		c.proxy = proxy
+		return nil
+	}
+}
+
+// WithTLSConfig - sets the TLS configuration used by the transport, i.e. for custom root CAs or client certificates.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		c.tlsConfig = tlsConfig.Clone()
+		return nil
+	}
+}
+
+// WithBaseURL - resolves relative request URLs against `base`, as a browser resolves links.
+// A base path should end with a slash to be kept, i.e. `https://api.example.com/v1/`.
+func WithBaseURL(base string) Option {
+	return func(c *config) error {
+		u, err := url.Parse(base)
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %w", err)
+		}
+
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid base URL: %q is not absolute", base)
+		}
+
+		c.baseURL = u
+		return nil
+	}
+}
+
+// WithTransport - sets the transport requests are sent with, ignoring the options configuring the default one.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *config) error {
+		c.transport = transport
+		return nil
+	}
+}
+
+func (c *config) newTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: c.dialTimeout, KeepAlive: DEFAULT_KEEP_ALIVE}
+
+	return &http.Transport{
+		Proxy:                 c.proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       c.tlsConfig,
+		TLSHandshakeTimeout:   c.tlsHandshakeTimeout,
+		ResponseHeaderTimeout: c.responseHeaderTimeout,
+		IdleConnTimeout:       c.idleConnTimeout,
+		MaxIdleConns:          c.maxIdleConns,
+		MaxIdleConnsPerHost:   c.maxIdleConnsPerHost,
+		MaxConnsPerHost:       c.maxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+}
+
+func (t *baseURLTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.URL.IsAbs() {
+		return t.next.RoundTrip(request)
+	}
+
+	// RoundTrippers must not modify the request they are given.
+	resolved := request.Clone(request.Context())
+	resolved.URL = t.base.ResolveReference(request.URL)
+	resolved.Host = ""
+
+	return t.next.RoundTrip(resolved)
+}
+
+func setDuration(target *time.Duration, name string, d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("invalid %s: %s", name, d)
+	}
+
+	*target = d
+	return nil
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	if New() != New() {
+		t.Errorf("New() returned different clients")
+	}
+
+	if New().Timeout != DEFAULT_TIMEOUT {
+		t.Errorf("New().Timeout = %v, want %v", New().Timeout, DEFAULT_TIMEOUT)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{
+			name: "transport settings",
+			options: []Option{
+				WithTimeout(0),
+				WithDialTimeout(time.Second),
+				WithTLSHandshakeTimeout(time.Second),
+				WithResponseHeaderTimeout(time.Second),
+				WithIdleConnTimeout(time.Minute),
+				WithMaxIdleConns(10, 2),
+				WithMaxConnsPerHost(4),
+				WithProxy(nil),
+				WithTLSConfig(nil),
+			},
+		},
+		{name: "negative timeout", options: []Option{WithTimeout(-time.Second)}, wantErr: true},
+		{name: "negative pool size", options: []Option{WithMaxIdleConns(-1, 0)}, wantErr: true},
+		{name: "relative base URL", options: []Option{WithBaseURL("/v1")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && client.Transport == nil {
+				t.Errorf("NewClient() returned a client without transport")
+			}
+		})
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	var got string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.String()
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithBaseURL(server.URL + "/v1/"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "users/42?expand=true", want: "/v1/users/42?expand=true"},
+		{url: "/health", want: "/health"},
+		{url: server.URL + "/absolute", want: "/absolute"},
+	}
+
+	for _, tt := range tests {
+		request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, tt.url, nil)
+
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatalf("Do(%s) error = %v", tt.url, err)
+		}
+
+		response.Body.Close()
+
+		if got != tt.want {
+			t.Errorf("Do(%s) requested %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+const (
+	DEFAULT_ERROR_BODY_LIMIT = 4 << 10
+	PROBLEM_CONTENT_TYPE     = "application/problem+json"
+)
+
+type (
+	// StatusError - describes a response outside of the 2xx range.
+	StatusError struct {
+		Method     string
+		URL        string
+		StatusCode int
+		Status     string
+		Header     http.Header
+
+		// First DEFAULT_ERROR_BODY_LIMIT bytes of the response body.
+		Body []byte
+
+		// Whether the response body was longer than Body.
+		Truncated bool
+
+		// Details of the error, if the response body is a problem+json document.
+		Problem *Problem
+	}
+
+	// Problem - problem details of an HTTP API error (RFC 7807).
+	Problem struct {
+		Type     string `json:"type,omitempty"`
+		Title    string `json:"title,omitempty"`
+		Status   int    `json:"status,omitempty"`
+		Detail   string `json:"detail,omitempty"`
+		Instance string `json:"instance,omitempty"`
+
+		// Members other than the standard ones, i.e. {"balance": 30}.
+		Extensions map[string]any `json:"-"`
+	}
+)
+
+// CheckResponse - returns a *StatusError if the status of the response is outside of the 2xx range, and nil otherwise.
+// The caller remains responsible for closing the response body.
+//
+// Usage:
+//
+//	response, err := client.Do(request)
+//	if err != nil {
+//		return err
+//	}
+//	defer response.Body.Close()
+//
+//	if err := httpclient.CheckResponse(response); err != nil {
+//		if httpclient.IsNotFound(err) {
+//			return nil
+//		}
+//
+//		return err
+//	}
+func CheckResponse(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return nil
+	}
+
+	return NewStatusError(response)
+}
+
+// NewStatusError - returns an error describing the response, reading up to DEFAULT_ERROR_BODY_LIMIT bytes of its body.
+func NewStatusError(response *http.Response) *StatusError {
+	e := &StatusError{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header,
+	}
+
+	if response.Request != nil {
+		e.Method = response.Request.Method
+		e.URL = response.Request.URL.Redacted()
+	}
+
+	if response.Body != nil {
+		data, _ := io.ReadAll(io.LimitReader(response.Body, DEFAULT_ERROR_BODY_LIMIT+1))
+		e.Truncated = len(data) > DEFAULT_ERROR_BODY_LIMIT
+		e.Body = data[:min(len(data), DEFAULT_ERROR_BODY_LIMIT)]
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType == PROBLEM_CONTENT_TYPE && !e.Truncated {
+		e.Problem, _ = parseProblem(e.Body)
+	}
+
+	return e
+}
+
+func (e *StatusError) Error() string {
+	status := e.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	message := fmt.Sprintf("%s %s: %s", e.Method, e.URL, status)
+
+	switch {
+	case e.Problem != nil && e.Problem.Detail != "":
+		return message + ": " + e.Problem.Detail
+	case e.Problem != nil && e.Problem.Title != "":
+		return message + ": " + e.Problem.Title
+	}
+
+	return message
+}
+
+// IsStatus - returns whether err is, or wraps, a *StatusError with one of the status codes.
+func IsStatus(err error, codes ...int) bool {
+	var e *StatusError
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	for _, code := range codes {
+		if e.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsNotFound - returns whether err is, or wraps, a 404 *StatusError.
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+// IsRetryable - returns whether the request that failed with err may succeed if sent again:
+// on the statuses retried by NewRetryTransport and on transient network errors, such as timeouts,
+// refused or reset connections and connections closed before the response was complete.
+//
+// Other errors returned by http.Client, i.e. for an unsupported scheme, a malformed URL, an invalid
+// certificate or too many redirects, would fail again and are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var e *StatusError
+	if errors.As(err, &e) {
+		return retryableStatus(e.StatusCode)
+	}
+
+	return isTransient(err)
+}
+
+// isTransient - returns whether err is a network failure that may not happen on another attempt.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+
+	// EOF means the server closed the connection before answering, i.e. a stale keep-alive connection.
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+func parseProblem(data []byte) (*Problem, error) {
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	problem := &Problem{}
+	if err := json.Unmarshal(data, problem); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, name)
+	}
+
+	if len(members) > 0 {
+		problem.Extensions = members
+	}
+
+	return problem, nil
+}
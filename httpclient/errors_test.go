@@ -0,0 +1,213 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        *StatusError
+	}{
+		{name: "success", status: http.StatusOK, body: `{}`},
+		{
+			name:   "plain",
+			status: http.StatusNotFound,
+			body:   "no such user",
+			want:   &StatusError{Method: "GET", StatusCode: 404, Status: "404 Not Found", Body: []byte("no such user")},
+		},
+		{
+			name:        "problem",
+			status:      http.StatusForbidden,
+			contentType: "application/problem+json; charset=utf-8",
+			body:        `{"type": "https://example.com/probs/out-of-credit", "title": "You do not have enough credit.", "status": 403, "detail": "Your balance is 30, but that costs 50.", "balance": 30}`,
+			want: &StatusError{
+				Method:     "GET",
+				StatusCode: 403,
+				Status:     "403 Forbidden",
+				Problem: &Problem{
+					Type:       "https://example.com/probs/out-of-credit",
+					Title:      "You do not have enough credit.",
+					Status:     403,
+					Detail:     "Your balance is 30, but that costs 50.",
+					Extensions: map[string]any{"balance": float64(30)},
+				},
+			},
+		},
+		{
+			name:        "invalid problem",
+			status:      http.StatusBadRequest,
+			contentType: "application/problem+json",
+			body:        `{"status": "400"}`,
+			want:        &StatusError{Method: "GET", StatusCode: 400, Status: "400 Bad Request", Body: []byte(`{"status": "400"}`)},
+		},
+		{
+			name:   "truncated",
+			status: http.StatusInternalServerError,
+			body:   strings.Repeat("x", DEFAULT_ERROR_BODY_LIMIT+1),
+			want: &StatusError{
+				Method:     "GET",
+				StatusCode: 500,
+				Status:     "500 Internal Server Error",
+				Body:       []byte(strings.Repeat("x", DEFAULT_ERROR_BODY_LIMIT)),
+				Truncated:  true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			response, err := New().Get(server.URL + "/users/42")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			defer response.Body.Close()
+
+			err = CheckResponse(response)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("CheckResponse() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var got *StatusError
+			if !errors.As(err, &got) {
+				t.Fatalf("CheckResponse() error = %v, want a *StatusError", err)
+			}
+
+			if got.URL != server.URL+"/users/42" || got.Header == nil {
+				t.Errorf("CheckResponse() URL = %s, header = %v", got.URL, got.Header)
+			}
+
+			got.URL, got.Header = "", nil
+			if tt.want.Problem != nil {
+				got.Body = nil
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *StatusError
+		want string
+	}{
+		{
+			name: "status",
+			err:  &StatusError{Method: "GET", URL: "https://example.com/users/42", StatusCode: 404, Status: "404 Not Found"},
+			want: "GET https://example.com/users/42: 404 Not Found",
+		},
+		{
+			name: "status code",
+			err:  &StatusError{Method: "GET", URL: "https://example.com", StatusCode: 503},
+			want: "GET https://example.com: 503 Service Unavailable",
+		},
+		{
+			name: "problem title",
+			err:  &StatusError{Method: "POST", URL: "https://example.com", Status: "409 Conflict", Problem: &Problem{Title: "Duplicate"}},
+			want: "POST https://example.com: 409 Conflict: Duplicate",
+		},
+		{
+			name: "problem detail",
+			err:  &StatusError{Method: "POST", URL: "https://example.com", Status: "409 Conflict", Problem: &Problem{Title: "Duplicate", Detail: "Email taken"}},
+			want: "POST https://example.com: 409 Conflict: Email taken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "not found", err: &StatusError{StatusCode: 404}, want: true},
+		{name: "wrapped", err: fmt.Errorf("fetching user: %w", &StatusError{StatusCode: 404}), want: true},
+		{name: "gone", err: &StatusError{StatusCode: 410}, want: false},
+		{name: "other error", err: errors.New("404"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.want {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	_, connectionErr := New().Get("http://127.0.0.1:0")
+	_, schemeErr := New().Get("ftp://example.com")
+	_, urlErr := New().Get("http://%zz")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "too many requests", err: &StatusError{StatusCode: 429}, want: true},
+		{name: "bad gateway", err: fmt.Errorf("wrapped: %w", &StatusError{StatusCode: 502}), want: true},
+		{name: "not implemented", err: &StatusError{StatusCode: 501}, want: false},
+		{name: "bad request", err: &StatusError{StatusCode: 400}, want: false},
+		{name: "connection error", err: connectionErr, want: true},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "unexpected EOF", err: &url.Error{Op: "Get", URL: "http://example.com", Err: io.ErrUnexpectedEOF}, want: true},
+		{name: "timeout", err: &url.Error{Op: "Get", URL: "http://example.com", Err: &net.DNSError{IsTimeout: true}}, want: true},
+		{name: "unknown host", err: &net.OpError{Op: "dial", Err: &net.DNSError{IsNotFound: true}}, want: false},
+		{name: "unsupported scheme", err: schemeErr, want: false},
+		{name: "malformed URL", err: urlErr, want: false},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "other error", err: errors.New("invalid input"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/oleoneto/go-toolkit/decoder"
+	"github.com/oleoneto/go-toolkit/validator"
+)
+
+const JSON_CONTENT_TYPE = "application/json"
+
+var ErrInvalidJSON = errors.New("invalid JSON response")
+
+type (
+	// JSONOption - configures how DoJSON decodes and validates responses.
+	JSONOption func(*jsonConfig)
+
+	jsonConfig struct {
+		header   http.Header
+		decoder  decoder.DecoderOptions
+		validate *validator.ValidationOptions
+	}
+)
+
+// DoJSON - sends `body` encoded as JSON, unless it is nil, and decodes the JSON object of a 2xx response into a T
+// with decoder.Decode. Non-2xx responses are returned as a *StatusError, and responses that are not valid JSON as
+// ErrInvalidJSON.
+//
+// The field-level errors map attributes to the problems found with them, i.e. {"id": ["REQUIRED_ATTRIBUTE_MISSING"]},
+// according to the decoder rules and, if enabled, the validation rules. Upstream API drift, such as missing, extra or
+// mistyped fields, is reported there rather than being silently decoded into zero values. The typed value is returned
+// along with them, filled with every field that could be decoded.
+//
+// Responses without content (204, or an empty body) return the zero value of T.
+//
+// Usage:
+//
+//	type User struct {
+//		Id    int    `json:"id" jsonschema:"required"`
+//		Email string `json:"email" validate:"email"`
+//	}
+//
+//	user, fields, err := httpclient.DoJSON[User](ctx, client, http.MethodGet, "users/42", nil,
+//		httpclient.WithDecoderOptions(decoder.DecoderOptions{
+//			Rules: []decoder.SchemaValidationRule{decoder.REQUIRED_ATTRIBUTE, decoder.INVALID_TYPE},
+//		}),
+//		httpclient.WithValidation(validator.ValidationOptions{}),
+//	)
+//
+//	// fields -> {"email": ["INVALID_FORMAT"]}
+func DoJSON[T any](ctx context.Context, client *http.Client, method, url string, body any, options ...JSONOption) (T, map[string][]string, error) {
+	var value T
+
+	c := &jsonConfig{}
+	for _, option := range options {
+		option(c)
+	}
+
+	if client == nil {
+		client = New()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return value, nil, fmt.Errorf("encoding request body: %w", err)
+		}
+	}
+
+	requestBody := NewBody(payload)
+
+	request, err := http.NewRequestWithContext(ctx, method, url, requestBody)
+	if err != nil {
+		return value, nil, err
+	}
+
+	for key, values := range c.header {
+		request.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	request.Header.Set("Accept", JSON_CONTENT_TYPE)
+
+	if len(payload) > 0 {
+		// Lets the client follow 307 and 308 redirects, and retry the request, with the same body.
+		request.GetBody = requestBody.(*Body).GetBody
+		request.ContentLength = int64(len(payload))
+		request.Header.Set("Content-Type", JSON_CONTENT_TYPE)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return value, nil, err
+	}
+
+	defer response.Body.Close()
+
+	if err := CheckResponse(response); err != nil {
+		return value, nil, err
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return value, nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return value, nil, nil
+	}
+
+	if !json.Valid(data) {
+		return value, nil, fmt.Errorf("%s %s: %w", method, request.URL.Redacted(), ErrInvalidJSON)
+	}
+
+	var fields map[string][]string
+
+	if c.validate != nil {
+		fields = validator.ValidatePayload(data, &value, validator.PayloadValidationOptions{
+			ValidationOptions: *c.validate,
+			DecoderOptions:    c.decoder,
+		})
+	} else {
+		fields = decoder.Decode(data, &value, c.decoder)
+	}
+
+	return value, fields, nil
+}
+
+// WithDecoderOptions - sets the rules and hooks responses are decoded with. See decoder.Decode.
+func WithDecoderOptions(options decoder.DecoderOptions) JSONOption {
+	return func(c *jsonConfig) { c.decoder = options }
+}
+
+// WithValidation - validates decoded responses with validator.Validate, reporting failures as field-level errors.
+func WithValidation(options validator.ValidationOptions) JSONOption {
+	return func(c *jsonConfig) { c.validate = &options }
+}
+
+// WithHeader - adds the headers to the request, i.e. for an Idempotency-Key.
+// Headers given by several options are merged.
+func WithHeader(header http.Header) JSONOption {
+	return func(c *jsonConfig) {
+		if c.header == nil {
+			c.header = http.Header{}
+		}
+
+		for key, values := range header {
+			for _, value := range values {
+				c.header.Add(key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/decoder"
+	"github.com/oleoneto/go-toolkit/validator"
+)
+
+type testUser struct {
+	Id    int    `json:"id" jsonschema:"required"`
+	Name  string `json:"name" jsonschema:"required"`
+	Email string `json:"email" validate:"email"`
+}
+
+func TestDoJSON(t *testing.T) {
+	rules := WithDecoderOptions(decoder.DecoderOptions{
+		Rules: []decoder.SchemaValidationRule{decoder.REQUIRED_ATTRIBUTE, decoder.INVALID_TYPE, decoder.ADDITIONAL_PROPERTY},
+	})
+
+	tests := []struct {
+		name       string
+		status     int
+		response   string
+		options    []JSONOption
+		want       testUser
+		wantFields map[string][]string
+		wantErr    bool
+	}{
+		{
+			name:       "valid",
+			status:     http.StatusOK,
+			response:   `{"id": 42, "name": "Ada", "email": "ada@example.com"}`,
+			options:    []JSONOption{rules},
+			want:       testUser{Id: 42, Name: "Ada", Email: "ada@example.com"},
+			wantFields: map[string][]string{},
+		},
+		{
+			name:       "without rules",
+			status:     http.StatusOK,
+			response:   `{"id": "42", "name": "Ada", "role": "admin"}`,
+			want:       testUser{Name: "Ada"},
+			wantFields: map[string][]string{},
+		},
+		{
+			name:     "drift",
+			status:   http.StatusOK,
+			response: `{"id": "42", "email": "ada@example.com", "role": "admin"}`,
+			options:  []JSONOption{rules},
+			want:     testUser{Email: "ada@example.com"},
+			wantFields: map[string][]string{
+				"id":   {"INVALID_TYPE"},
+				"name": {"REQUIRED_ATTRIBUTE_MISSING"},
+				"role": {"ADDITIONAL_PROPERTY"},
+			},
+		},
+		{
+			name:       "validation",
+			status:     http.StatusCreated,
+			response:   `{"id": 42, "name": "Ada", "email": "ada"}`,
+			options:    []JSONOption{rules, WithValidation(validator.ValidationOptions{})},
+			want:       testUser{Id: 42, Name: "Ada", Email: "ada"},
+			wantFields: map[string][]string{"email": {"INVALID_FORMAT"}},
+		},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "error status", status: http.StatusBadGateway, response: `{"error": "upstream"}`, wantErr: true},
+		{name: "invalid JSON", status: http.StatusOK, response: `<html></html>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			got, fields, err := DoJSON[testUser](context.Background(), nil, http.MethodGet, server.URL, nil, tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DoJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.status >= 300 && !IsStatus(err, tt.status) {
+				t.Errorf("DoJSON() error = %v, want a %d *StatusError", err, tt.status)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DoJSON() = %+v, want %+v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("DoJSON() fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestDoJSON_request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		if string(data) != `{"id":0,"name":"Ada","email":""}` ||
+			r.Header.Get("Content-Type") != JSON_CONTENT_TYPE ||
+			r.Header.Get("Accept") != JSON_CONTENT_TYPE ||
+			r.Header.Get("Idempotency-Key") != "42" ||
+			r.Header.Get("X-Tenant") != "acme" {
+			t.Errorf("DoJSON() sent %s with headers %v", data, r.Header)
+		}
+
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	body := testUser{Name: "Ada"}
+
+	got, _, err := DoJSON[testUser](context.Background(), New(), http.MethodPost, server.URL, body,
+		WithHeader(http.Header{"Idempotency-Key": {"42"}}),
+		WithHeader(http.Header{"X-Tenant": {"acme"}}),
+	)
+	if err != nil {
+		t.Fatalf("DoJSON() error = %v", err)
+	}
+
+	if got != body {
+		t.Errorf("DoJSON() = %+v, want %+v", got, body)
+	}
+
+	if _, _, err := DoJSON[testUser](context.Background(), nil, http.MethodPost, server.URL, func() {}); err == nil {
+		t.Errorf("DoJSON() error = nil, want an encoding error")
+	}
+
+	if _, _, err := DoJSON[testUser](context.Background(), nil, http.MethodGet, "http://127.0.0.1:0", nil); err == nil || errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("DoJSON() error = %v, want a connection error", err)
+	}
+}
+
+func TestDoJSON_redirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/v2/users", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/v2/users", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Write(data)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	body := testUser{Id: 1, Name: "Ada"}
+
+	got, _, err := DoJSON[testUser](context.Background(), nil, http.MethodPost, server.URL+"/users", body)
+	if err != nil {
+		t.Fatalf("DoJSON() error = %v", err)
+	}
+
+	if got != body {
+		t.Errorf("DoJSON() = %+v, want %+v sent again after the redirect", got, body)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	c := &jsonConfig{}
+	WithHeader(http.Header{"Idempotency-Key": {"42"}, "X-Tag": {"a"}})(c)
+	WithHeader(http.Header{"x-tag": {"b"}})(c)
+
+	want := http.Header{"Idempotency-Key": {"42"}, "X-Tag": {"a", "b"}}
+	if !reflect.DeepEqual(c.header, want) {
+		t.Errorf("WithHeader() = %v, want %v", c.header, want)
+	}
+}
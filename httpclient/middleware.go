@@ -0,0 +1,195 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const DEFAULT_REQUEST_ID_HEADER = "X-Request-Id"
+
+type (
+	// Middleware - wraps a transport to act on every request it sends or every response it receives.
+	Middleware func(next http.RoundTripper) http.RoundTripper
+
+	// RoundTripperFunc - adapter allowing functions to be used as transports.
+	RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+	// TimingFunc - receives the outcome and duration of every request.
+	TimingFunc func(request *http.Request, response *http.Response, err error, duration time.Duration)
+)
+
+func (f RoundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) { return f(request) }
+
+// Chain - wraps `transport` in the middlewares. The first middleware is the outermost one,
+// so it sees requests first and responses last.
+//
+// Usage:
+//
+//	transport := httpclient.Chain(http.DefaultTransport, httpclient.RequestID("", nil), httpclient.Logging(logger))
+func Chain(transport http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+
+	return transport
+}
+
+// WithMiddleware - wraps the transport of the client in the middlewares, as Chain does.
+// Middlewares run once per request, outside of retries, and see URLs already resolved against the base URL.
+//
+// Usage:
+//
+//	client, err := httpclient.NewClient(
+//		httpclient.WithBaseURL("https://api.example.com/v1/"),
+//		httpclient.WithMiddleware(
+//			httpclient.UserAgent("billing/1.4"),
+//			httpclient.RequestID("", nil),
+//			httpclient.BearerToken(tokens.Get),
+//			httpclient.Logging(logger),
+//		),
+//	)
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *config) error {
+		c.middlewares = append(c.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// DefaultHeaders - sets the headers on requests that do not already have them.
+func DefaultHeaders(header http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			request = cloneHeader(request)
+
+			for key, values := range header {
+				if _, ok := request.Header[http.CanonicalHeaderKey(key)]; !ok {
+					request.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+				}
+			}
+
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+// UserAgent - sets the User-Agent header on requests that do not already have one.
+func UserAgent(agent string) Middleware {
+	return DefaultHeaders(http.Header{"User-Agent": {agent}})
+}
+
+// RequestID - sets a request id header on requests that do not already have one, so that they can be
+// traced across services. The header defaults to DEFAULT_REQUEST_ID_HEADER and ids to random UUIDs.
+func RequestID(header string, generate func() string) Middleware {
+	if header == "" {
+		header = DEFAULT_REQUEST_ID_HEADER
+	}
+
+	if generate == nil {
+		generate = uuid.NewString
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			if request.Header.Get(header) == "" {
+				request = cloneHeader(request)
+				request.Header.Set(header, generate())
+			}
+
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+// BearerToken - sets the Authorization header of every request to a bearer token returned by `token`,
+// which is called for each request so that tokens can be cached and refreshed by the caller.
+func BearerToken(token func(ctx context.Context) (string, error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			value, err := token(request.Context())
+			if err != nil {
+				closeBody(request)
+				return nil, err
+			}
+
+			request = cloneHeader(request)
+			request.Header.Set("Authorization", "Bearer "+value)
+
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+// BasicAuth - sets the Authorization header of every request to the HTTP basic credentials.
+func BasicAuth(username, password string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			request = cloneHeader(request)
+			request.SetBasicAuth(username, password)
+
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+// Timing - reports every request to `observe` once its response headers were received or it failed,
+// i.e. to record metrics.
+func Timing(observe TimingFunc) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next.RoundTrip(request)
+			observe(request, response, err, time.Since(start))
+
+			return response, err
+		})
+	}
+}
+
+// Logging - logs every request with its method, URL, status and duration. Passwords in URLs are redacted.
+// Failed requests are logged at zerolog.ErrorLevel, error responses at zerolog.WarnLevel and others at zerolog.InfoLevel.
+func Logging(logger zerolog.Logger) Middleware {
+	return Timing(func(request *http.Request, response *http.Response, err error, duration time.Duration) {
+		var event *zerolog.Event
+
+		switch {
+		case err != nil:
+			event = logger.Error().Err(err)
+		case response.StatusCode >= 400:
+			event = logger.Warn().Int("status", response.StatusCode)
+		default:
+			event = logger.Info().Int("status", response.StatusCode)
+		}
+
+		event.
+			Str("method", request.Method).
+			Str("url", request.URL.Redacted()).
+			Dur("duration", duration).
+			Msg("http request")
+	})
+}
+
+// cloneHeader - returns a copy of the request whose header can be modified, since
+// RoundTrippers must not modify the request they are given.
+func cloneHeader(request *http.Request) *http.Request {
+	clone := request.Clone(request.Context())
+	if clone.Header == nil {
+		clone.Header = http.Header{}
+	}
+
+	return clone
+}
+
+// closeBody - closes the request body, as RoundTrippers must even when they fail.
+func closeBody(request *http.Request) {
+	if request.Body != nil {
+		request.Body.Close()
+	}
+}
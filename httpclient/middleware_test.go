@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, "before "+name)
+				response, err := next.RoundTrip(request)
+				calls = append(calls, "after "+name)
+				return response, err
+			})
+		}
+	}
+
+	transport := Chain(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		calls = append(calls, "transport")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), trace("outer"), trace("inner"))
+
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+	if _, err := transport.RoundTrip(request); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+
+	want := []string{"before outer", "before inner", "transport", "after inner", "after outer"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("RoundTrip() called %v, want %v", calls, want)
+	}
+}
+
+func TestMiddleware_headers(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware Middleware
+		header     http.Header
+		want       http.Header
+	}{
+		{
+			name:       "default headers",
+			middleware: DefaultHeaders(http.Header{"accept": {"application/json"}, "X-Tenant": {"acme"}}),
+			header:     http.Header{"X-Tenant": {"globex"}},
+			want:       http.Header{"Accept": {"application/json"}, "X-Tenant": {"globex"}},
+		},
+		{
+			name:       "user agent",
+			middleware: UserAgent("billing/1.4"),
+			want:       http.Header{"User-Agent": {"billing/1.4"}},
+		},
+		{
+			name:       "generated request id",
+			middleware: RequestID("", func() string { return "42" }),
+			want:       http.Header{"X-Request-Id": {"42"}},
+		},
+		{
+			name:       "existing request id",
+			middleware: RequestID("X-Correlation-Id", func() string { return "42" }),
+			header:     http.Header{"X-Correlation-Id": {"7"}},
+			want:       http.Header{"X-Correlation-Id": {"7"}},
+		},
+		{
+			name:       "bearer token",
+			middleware: BearerToken(func(context.Context) (string, error) { return "secret", nil }),
+			want:       http.Header{"Authorization": {"Bearer secret"}},
+		},
+		{
+			name:       "basic auth",
+			middleware: BasicAuth("user", "pass"),
+			want:       http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+
+			transport := tt.middleware(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				got = request.Header
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}))
+
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+			for key, values := range tt.header {
+				request.Header[key] = values
+			}
+
+			original := request.Header.Clone()
+
+			if _, err := transport.RoundTrip(request); err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RoundTrip() sent %v, want %v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(request.Header, original) {
+				t.Errorf("RoundTrip() modified the request header to %v", request.Header)
+			}
+		})
+	}
+}
+
+func TestBearerToken_error(t *testing.T) {
+	transport := BearerToken(func(context.Context) (string, error) { return "", errors.New("token expired") })(http.DefaultTransport)
+
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+	if _, err := transport.RoundTrip(request); err == nil {
+		t.Errorf("RoundTrip() error = nil, want the token error")
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "billing/1.4" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	var timed time.Duration
+
+	client, err := NewClient(
+		WithBaseURL(server.URL),
+		WithMiddleware(
+			UserAgent("billing/1.4"),
+			Logging(zerolog.New(&output)),
+			Timing(func(_ *http.Request, _ *http.Response, _ error, d time.Duration) { timed = d }),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/invoices?page=2", nil)
+
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Do() status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+
+	if timed <= 0 {
+		t.Errorf("Timing() observed %v, want a positive duration", timed)
+	}
+
+	for _, want := range []string{`"level":"info"`, `"status":200`, `"method":"GET"`, `"url":"` + server.URL + `/invoices?page=2"`} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Logging() wrote %s, want %s", output.String(), want)
+		}
+	}
+}
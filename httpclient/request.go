@@ -6,17 +6,42 @@ import (
 	"net/http"
 )
 
-type Body struct{ state bytes.Buffer }
+// Body - request body that can be read again, so requests using it can be retried.
+type Body struct {
+	data   []byte
+	reader *bytes.Reader
+}
+
+func (b *Body) Close() error { return nil }
 
-func (b *Body) Close() error { return io.NopCloser(&b.state).Close() }
+func (b *Body) Read(buffer []byte) (int, error) { return b.reader.Read(buffer) }
 
-func (b *Body) Read(buffer []byte) (int, error) { return b.state.Read(buffer) }
+// Len - returns the number of bytes of the body, read or not.
+func (b *Body) Len() int { return len(b.data) }
 
+// GetBody - returns a new copy of the body, unread. It matches http.Request.GetBody.
+func (b *Body) GetBody() (io.ReadCloser, error) { return NewBody(b.data), nil }
+
+// NewBody - returns a replayable request body holding `data`, or http.NoBody if it is empty.
+//
+// http.NewRequest only sets GetBody for the body types it knows, so it should be set from the *Body
+// as well. Otherwise, http.Client cannot follow 307 and 308 redirects with the body.
+//
+// Usage:
+//
+//	body := httpclient.NewBody(payload)
+//	request, _ := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+//	request.ContentLength = int64(len(payload))
+//
+//	if b, ok := body.(*httpclient.Body); ok {
+//		request.GetBody = b.GetBody
+//	}
 func NewBody(data []byte) io.ReadCloser {
-	if data == nil || len(data) < 1 {
+	if len(data) < 1 {
 		return http.NoBody
 	}
-	return &Body{*bytes.NewBuffer(data)}
+
+	return &Body{data: data, reader: bytes.NewReader(data)}
 }
 
 // Body implements io.ReadCloser
@@ -0,0 +1,204 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_MAX_RETRIES     = 3
+	DEFAULT_RETRY_BASE_WAIT = 100 * time.Millisecond
+	DEFAULT_RETRY_MAX_WAIT  = 10 * time.Second
+)
+
+// Methods that can be sent more than once without further effect (RFC 9110, section 9.2.2).
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+type (
+	RetryPolicy struct {
+		// Number of times a request is retried after its first attempt. Zero disables retries,
+		// except in a zero-valued policy, which retries DEFAULT_MAX_RETRIES times.
+		MaxRetries int
+
+		// Wait before the first retry, doubled on every subsequent one. Defaults to DEFAULT_RETRY_BASE_WAIT.
+		BaseWait time.Duration
+
+		// Longest wait between two attempts. Responses whose Retry-After asks for a longer wait are
+		// returned instead of being retried. Defaults to DEFAULT_RETRY_MAX_WAIT.
+		MaxWait time.Duration
+	}
+
+	retryTransport struct {
+		next   http.RoundTripper
+		policy RetryPolicy
+		jitter func(time.Duration) time.Duration
+	}
+)
+
+// NewRetryTransport - returns a transport retrying idempotent requests that fail with a transient
+// network error (see IsRetryable), a 429 or a 5xx response (except 501), waiting with exponential
+// backoff and jitter or for the duration given by the Retry-After header.
+//
+// Requests are idempotent if their method is, or if they carry an Idempotency-Key header.
+// Requests with a body are only retried if it can be read again: bodies created by NewBody,
+// or by http.NewRequest from a *bytes.Buffer, *bytes.Reader or *strings.Reader.
+//
+// Usage:
+//
+//	client, err := httpclient.NewClient(httpclient.WithRetry(httpclient.RetryPolicy{MaxRetries: 5}))
+func NewRetryTransport(next http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if policy == (RetryPolicy{}) {
+		policy.MaxRetries = DEFAULT_MAX_RETRIES
+	}
+
+	if policy.BaseWait <= 0 {
+		policy.BaseWait = DEFAULT_RETRY_BASE_WAIT
+	}
+
+	if policy.MaxWait <= 0 {
+		policy.MaxWait = DEFAULT_RETRY_MAX_WAIT
+	}
+
+	// Equal jitter: half of the wait is kept so that waits still grow, the other half is random.
+	jitter := func(d time.Duration) time.Duration {
+		if d <= 0 {
+			return 0
+		}
+
+		return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+
+	return &retryTransport{next: next, policy: policy, jitter: jitter}
+}
+
+// WithRetry - retries failed requests according to the policy. See NewRetryTransport.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *config) error {
+		c.retry = &policy
+		return nil
+	}
+}
+
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	getBody, replayable := bodyGetter(request)
+	if !replayable || !isIdempotent(request) {
+		return t.next.RoundTrip(request)
+	}
+
+	ctx := request.Context()
+	attempt := request
+
+	for retry := 0; ; retry++ {
+		response, err := t.next.RoundTrip(attempt)
+
+		if retry >= t.policy.MaxRetries || !shouldRetry(ctx, response, err) {
+			return response, err
+		}
+
+		wait := t.jitter(t.policy.backoff(retry))
+
+		if response != nil {
+			if after, ok := retryAfter(response, time.Now()); ok {
+				if after > t.policy.MaxWait {
+					return response, err
+				}
+
+				wait = after
+			}
+
+			// Draining the body lets the connection be reused.
+			io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+			response.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		// RoundTrippers must not modify the request they are given, so each retry sends a copy.
+		attempt = request.Clone(ctx)
+		if getBody != nil {
+			if attempt.Body, err = getBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// backoff - returns BaseWait doubled `retry` times, up to MaxWait. Doubling stops once MaxWait is reached,
+// so the wait cannot overflow however many retries the policy allows.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	if retry >= 62 || p.BaseWait > p.MaxWait>>retry {
+		return p.MaxWait
+	}
+
+	return p.BaseWait << retry
+}
+
+// bodyGetter - returns how to read the request body again, and whether it can be.
+func bodyGetter(request *http.Request) (func() (io.ReadCloser, error), bool) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, true
+	}
+
+	if request.GetBody != nil {
+		return request.GetBody, true
+	}
+
+	if body, ok := request.Body.(*Body); ok {
+		return body.GetBody, true
+	}
+
+	return nil, false
+}
+
+func isIdempotent(request *http.Request) bool {
+	return idempotentMethods[request.Method] || request.Header.Get("Idempotency-Key") != ""
+}
+
+func shouldRetry(ctx context.Context, response *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && isTransient(err)
+	}
+
+	return retryableStatus(response.StatusCode)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
+
+// retryAfter - returns the wait requested by the Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(response *http.Response, now time.Time) (time.Duration, bool) {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
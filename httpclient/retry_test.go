@@ -0,0 +1,301 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestRetryTransport(policy RetryPolicy) *retryTransport {
+	transport := NewRetryTransport(nil, policy).(*retryTransport)
+	transport.jitter = func(time.Duration) time.Duration { return 0 }
+	return transport
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		header     http.Header
+		body       func(data string) io.Reader
+		statuses   []int
+		wantCalls  int
+		wantStatus int
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantCalls: 1, wantStatus: 200},
+		{name: "server errors", method: http.MethodGet, statuses: []int{503, 500, 200}, wantCalls: 3, wantStatus: 200},
+		{name: "too many requests", method: http.MethodDelete, statuses: []int{429, 204}, wantCalls: 2, wantStatus: 204},
+		{name: "retries exhausted", method: http.MethodGet, statuses: []int{502, 502, 502, 502, 200}, wantCalls: 3, wantStatus: 502},
+		{name: "not implemented", method: http.MethodGet, statuses: []int{501, 200}, wantCalls: 1, wantStatus: 501},
+		{name: "client error", method: http.MethodGet, statuses: []int{404, 200}, wantCalls: 1, wantStatus: 404},
+		{name: "not idempotent", method: http.MethodPost, statuses: []int{503, 200}, wantCalls: 1, wantStatus: 503},
+		{
+			name:       "idempotency key",
+			method:     http.MethodPost,
+			header:     http.Header{"Idempotency-Key": {"42"}},
+			body:       func(data string) io.Reader { return NewBody([]byte(data)) },
+			statuses:   []int{503, 200},
+			wantCalls:  2,
+			wantStatus: 200,
+		},
+		{
+			name:       "replayable body",
+			method:     http.MethodPut,
+			body:       func(data string) io.Reader { return NewBody([]byte(data)) },
+			statuses:   []int{500, 500, 200},
+			wantCalls:  3,
+			wantStatus: 200,
+		},
+		{
+			name:       "one-shot body",
+			method:     http.MethodPut,
+			body:       func(data string) io.Reader { return io.NopCloser(strings.NewReader(data)) },
+			statuses:   []int{500, 200},
+			wantCalls:  1,
+			wantStatus: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if data, _ := io.ReadAll(r.Body); tt.body != nil && string(data) != tt.name {
+					t.Errorf("attempt %d received body %q, want %q", calls+1, data, tt.name)
+				}
+
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			var body io.Reader
+			if tt.body != nil {
+				body = tt.body(tt.name)
+			}
+
+			request, _ := http.NewRequestWithContext(context.Background(), tt.method, server.URL, body)
+			for key, values := range tt.header {
+				request.Header[key] = values
+			}
+
+			response, err := newTestRetryTransport(RetryPolicy{MaxRetries: 2}).RoundTrip(request)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+
+			response.Body.Close()
+
+			if calls != tt.wantCalls || response.StatusCode != tt.wantStatus {
+				t.Errorf("RoundTrip() = %d after %d calls, want %d after %d calls", response.StatusCode, calls, tt.wantStatus, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNewRetryTransport_policy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		want      RetryPolicy
+		wantCalls int
+	}{
+		{
+			name:      "zero-valued",
+			want:      RetryPolicy{MaxRetries: DEFAULT_MAX_RETRIES, BaseWait: DEFAULT_RETRY_BASE_WAIT, MaxWait: DEFAULT_RETRY_MAX_WAIT},
+			wantCalls: DEFAULT_MAX_RETRIES + 1,
+		},
+		{
+			name:      "retries disabled",
+			policy:    RetryPolicy{BaseWait: time.Millisecond},
+			want:      RetryPolicy{BaseWait: time.Millisecond, MaxWait: DEFAULT_RETRY_MAX_WAIT},
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := newTestRetryTransport(tt.policy)
+			if transport.policy != tt.want {
+				t.Fatalf("NewRetryTransport() policy = %+v, want %+v", transport.policy, tt.want)
+			}
+
+			calls := 0
+			transport.next = RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+			})
+
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+
+			response, err := transport.RoundTrip(request)
+			if err != nil || response.StatusCode != http.StatusServiceUnavailable || calls != tt.wantCalls {
+				t.Errorf("RoundTrip() = %v, %v after %d calls, want a 503 after %d calls", response, err, calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_connectionError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "connection reset", err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, wantCalls: 3},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), wantCalls: 3},
+		{name: "unsupported scheme", err: errors.New(`unsupported protocol scheme "ftp"`), wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+
+			transport := newTestRetryTransport(RetryPolicy{MaxRetries: 2})
+			transport.next = RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				calls++
+				return nil, tt.err
+			})
+
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+
+			if _, err := transport.RoundTrip(request); err == nil || calls != tt.wantCalls {
+				t.Errorf("RoundTrip() error = %v after %d calls, want an error after %d calls", err, calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_manyRetries(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 100, BaseWait: time.Second, MaxWait: time.Minute}
+
+	calls := 0
+	waits := []time.Duration{}
+
+	transport := newTestRetryTransport(policy)
+	transport.next = RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+	transport.jitter = func(d time.Duration) time.Duration {
+		waits = append(waits, d)
+		return 0
+	}
+
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com", nil)
+
+	response, err := transport.RoundTrip(request)
+	if err != nil || response.StatusCode != http.StatusServiceUnavailable || calls != 101 {
+		t.Fatalf("RoundTrip() = %v, %v after %d calls, want a 503 after 101 calls", response, err, calls)
+	}
+
+	for retry, wait := range waits {
+		if wait <= 0 || wait > policy.MaxWait || (retry > 0 && wait < waits[retry-1]) {
+			t.Fatalf("wait before retry %d = %v, want waits growing up to %v: %v", retry+1, wait, policy.MaxWait, waits)
+		}
+	}
+
+	if last := waits[len(waits)-1]; last != policy.MaxWait {
+		t.Errorf("last wait = %v, want %v", last, policy.MaxWait)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{BaseWait: 100 * time.Millisecond, MaxWait: 10 * time.Second}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{retry: 0, want: 100 * time.Millisecond},
+		{retry: 3, want: 800 * time.Millisecond},
+		{retry: 7, want: 10 * time.Second},
+		{retry: 63, want: 10 * time.Second},
+		{retry: 1000, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.backoff(tt.retry); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+		}
+	}
+
+	jitter := NewRetryTransport(nil, policy).(*retryTransport).jitter
+	for _, d := range []time.Duration{-time.Second, 0, 1, time.Minute} {
+		if got := jitter(d); got < d/2 || got > max(d, 0) {
+			t.Errorf("jitter(%v) = %v, want a wait between %v and %v", d, got, d/2, max(d, 0))
+		}
+	}
+}
+
+func TestRetryTransport_retryAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantCalls int
+	}{
+		{name: "seconds", value: "0", wantCalls: 2},
+		{name: "date", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), wantCalls: 2},
+		{name: "longer than max wait", value: "120", wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.Header().Set("Retry-After", tt.value)
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+
+			// Without Retry-After, the wait would exceed the test timeout.
+			transport := newTestRetryTransport(RetryPolicy{MaxRetries: 1, BaseWait: time.Hour, MaxWait: time.Minute})
+			transport.jitter = func(d time.Duration) time.Duration { return d }
+
+			response, err := transport.RoundTrip(request)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+
+			response.Body.Close()
+
+			if calls != tt.wantCalls {
+				t.Errorf("RoundTrip() made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	transport := newTestRetryTransport(RetryPolicy{MaxRetries: 1, BaseWait: time.Hour, MaxWait: time.Hour})
+	transport.jitter = func(d time.Duration) time.Duration { return d }
+
+	if _, err := transport.RoundTrip(request); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RoundTrip() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,20 @@
+package sqldb
+
+import "time"
+
+const (
+	DEFAULT_RETRY_DELAY     = time.Second
+	DEFAULT_MAX_RETRY_DELAY = time.Hour
+)
+
+// ExponentialBackoff - returns DEFAULT_RETRY_DELAY doubled for every attempt after the first, up to DEFAULT_MAX_RETRY_DELAY.
+//
+// This is the default delay before retrying failed jobs (see package jobs) and outbox events (see package outbox).
+func ExponentialBackoff(attempt int) time.Duration {
+	delay := DEFAULT_RETRY_DELAY
+	for i := 1; i < attempt && delay < DEFAULT_MAX_RETRY_DELAY; i++ {
+		delay *= 2
+	}
+
+	return min(delay, DEFAULT_MAX_RETRY_DELAY)
+}
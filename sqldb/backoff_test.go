@@ -0,0 +1,24 @@
+package sqldb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 100, want: DEFAULT_MAX_RETRY_DELAY},
+	}
+
+	for _, tt := range tests {
+		if got := ExponentialBackoff(tt.attempt); got != tt.want {
+			t.Errorf("ExponentialBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/oleoneto/go-toolkit/helpers"
+)
+
+type ConflictAction string
+
+const (
+	CONFLICT_ERROR  ConflictAction = ""
+	CONFLICT_IGNORE ConflictAction = "ignore"
+	CONFLICT_UPDATE ConflictAction = "update"
+
+	// Maximum number of bind parameters in a single statement.
+	POSTGRESQL_MAX_PARAMETERS = 65535
+	SQLITE3_MAX_PARAMETERS    = 999
+)
+
+var errCopyUnsupported = fmt.Errorf("connection does not support COPY: %w", errors.ErrUnsupported)
+
+type (
+	// Implemented by *sql.DB, which can hand out a dedicated connection.
+	connector interface {
+		Conn(context.Context) (*sql.Conn, error)
+	}
+
+	// Implemented by *pgx.Conn and pgx.Tx.
+	copier interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+		CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+	}
+)
+
+type BulkInsertOptions struct {
+	Adapter SQLAdapter
+	Table   string
+
+	// Subset of columns to write. Defaults to every column of the struct (see ScanRows), excluding nested structs.
+	Columns []string
+
+	// What to do with rows that violate a unique constraint. Defaults to failing the insert.
+	OnConflict ConflictAction
+
+	// Unique columns checked for conflicts. Required by CONFLICT_UPDATE.
+	ConflictColumns []string
+
+	// Columns overwritten on conflict. Defaults to every written column not in ConflictColumns.
+	// CONFLICT_UPDATE fails if no column is left to overwrite.
+	UpdateColumns []string
+
+	// Maximum number of bind parameters per INSERT statement. Defaults to the adapter's limit.
+	MaxParameters int
+}
+
+// BulkInsert - writes the rows into the table and returns the number of rows written.
+//
+// On Postgres, rows are streamed with COPY FROM whenever `db` is a *sql.DB backed by pgx, or a Cluster
+// over one. Conflicts are then resolved by copying into a temporary table first. Otherwise, rows are written
+// with multi-row INSERT statements inside a single transaction, each sized to the adapter's parameter limit.
+// Rows skipped due to CONFLICT_IGNORE are not counted as written.
+//
+// Table and column names are quoted on every path, so they are matched case-sensitively. With CONFLICT_UPDATE,
+// rows sharing the same conflict columns are merged into the last of them before being written, since Postgres
+// cannot update a row twice in one statement.
+//
+// Usage:
+//
+//	written, err := BulkInsert(ctx, db, users, BulkInsertOptions{
+//		Adapter:         SQLite3,
+//		Table:           "users",
+//		OnConflict:      CONFLICT_UPDATE,
+//		ConflictColumns: []string{"email"},
+//	})
+func BulkInsert[T any](ctx context.Context, db SqlBackend, rows []T, options BulkInsertOptions) (int64, error) {
+	if _, ok := drivers[options.Adapter]; !ok {
+		return 0, fmt.Errorf("unsupported adapter: %q", options.Adapter)
+	}
+
+	if !IdentifierValidationPattern.MatchString(options.Table) {
+		return 0, fmt.Errorf("invalid table name: %q", options.Table)
+	}
+
+	columns, paths, err := insertColumns(reflect.TypeOf((*T)(nil)).Elem(), options.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, column := range append(append([]string{}, options.ConflictColumns...), options.UpdateColumns...) {
+		if !IdentifierValidationPattern.MatchString(column) {
+			return 0, fmt.Errorf("invalid column name: %q", column)
+		}
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	values := make([][]any, len(rows))
+	for i, row := range rows {
+		rv := reflect.ValueOf(row)
+		values[i] = helpers.Map(paths, func(_ int, path []int) any { return valueByPath(rv, path) })
+	}
+
+	conflict, err := conflictClause(options, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	if options.OnConflict == CONFLICT_UPDATE {
+		values = lastByConflict(values, columns, options.ConflictColumns)
+	}
+
+	// COPY runs on a connection of its own, so it cannot take part in a transaction started by WithTx.
+	if pool, ok := db.(connector); ok && options.Adapter == PostgreSQL {
+		if _, inTx := TxFromContext(ctx); !inTx {
+			written, err := copyFrom(ctx, pool, options.Table, columns, values, conflict)
+			if !errors.Is(err, errors.ErrUnsupported) {
+				return written, err
+			}
+		}
+	}
+
+	return insertInBatches(ctx, db, options, columns, values, conflict)
+}
+
+// insertColumns - returns the names and field paths of the columns written for the struct type.
+func insertColumns(t reflect.Type, selected []string) ([]string, [][]int, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if !isNestedStruct(t) {
+		return nil, nil, fmt.Errorf("bulk insert requires a struct type, got %s", t)
+	}
+
+	mapping := columnsOf(t)
+
+	if len(selected) == 0 {
+		selected = helpers.Filter(mapping.names, func(_ int, name string) bool { return !strings.Contains(name, ".") })
+	}
+
+	paths := make([][]int, len(selected))
+	for i, column := range selected {
+		path, ok := mapping.fields[strings.ToLower(column)]
+		if !ok || !IdentifierValidationPattern.MatchString(column) || strings.Contains(column, ".") {
+			return nil, nil, fmt.Errorf("no insertable field in %s matches column %q", t, column)
+		}
+
+		paths[i] = path
+	}
+
+	if len(selected) == 0 {
+		return nil, nil, fmt.Errorf("no columns to insert for %s", t)
+	}
+
+	return selected, paths, nil
+}
+
+func conflictClause(options BulkInsertOptions, columns []string) (string, error) {
+	target := ""
+	if len(options.ConflictColumns) > 0 {
+		target = " (" + quoteIdentifiers(options.ConflictColumns) + ")"
+	}
+
+	switch options.OnConflict {
+	case CONFLICT_ERROR:
+		return "", nil
+	case CONFLICT_IGNORE:
+		return " ON CONFLICT" + target + " DO NOTHING", nil
+	case CONFLICT_UPDATE:
+		if target == "" {
+			return "", fmt.Errorf("%s on conflict requires conflict columns", CONFLICT_UPDATE)
+		}
+
+		update := options.UpdateColumns
+		if len(update) == 0 {
+			update = helpers.Filter(columns, func(_ int, column string) bool {
+				return !helpers.Contains(options.ConflictColumns, column)
+			})
+		}
+
+		if len(update) == 0 {
+			return "", fmt.Errorf("%s on conflict has no columns to update, use %s instead", CONFLICT_UPDATE, CONFLICT_IGNORE)
+		}
+
+		sets := helpers.Map(update, func(_ int, column string) string {
+			return fmt.Sprintf("%s = excluded.%s", quoteIdentifier(column), quoteIdentifier(column))
+		})
+
+		return " ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(sets, ", "), nil
+	}
+
+	return "", fmt.Errorf("unsupported conflict action: %q", options.OnConflict)
+}
+
+func insertInBatches(ctx context.Context, db SqlBackend, options BulkInsertOptions, columns []string, values [][]any, conflict string) (int64, error) {
+	maxParameters := options.MaxParameters
+	if maxParameters <= 0 {
+		maxParameters = SQLITE3_MAX_PARAMETERS
+		if options.Adapter == PostgreSQL {
+			maxParameters = POSTGRESQL_MAX_PARAMETERS
+		}
+	}
+
+	batchSize := max(1, maxParameters/len(columns))
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", quoteIdentifier(options.Table), quoteIdentifiers(columns))
+
+	var written int64
+
+	err := WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+		written = 0
+
+		for start := 0; start < len(values); start += batchSize {
+			batch := values[start:min(start+batchSize, len(values))]
+
+			tuples := make([]string, len(batch))
+			args := make([]any, 0, len(batch)*len(columns))
+
+			for i, row := range batch {
+				tuples[i] = "(" + helpers.EnumerateArgsOffset(len(row), len(args), func(index, _ int) string {
+					return options.Adapter.Placeholder(index)
+				}) + ")"
+
+				args = append(args, row...)
+			}
+
+			result, err := tx.ExecContext(ctx, prefix+strings.Join(tuples, ", ")+conflict, args...)
+			if err != nil {
+				return err
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			written += affected
+		}
+
+		return nil
+	})
+
+	return written, err
+}
+
+func copyFrom(ctx context.Context, pool connector, table string, columns []string, values [][]any, conflict string) (int64, error) {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var written int64
+
+	err = conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errCopyUnsupported
+		}
+
+		pgxConn := stdConn.Conn()
+
+		if conflict == "" {
+			written, err = copyRows(ctx, pgxConn, table, columns, values, conflict)
+			return err
+		}
+
+		return pgx.BeginFunc(ctx, pgxConn, func(tx pgx.Tx) error {
+			written, err = copyRows(ctx, tx, table, columns, values, conflict)
+			return err
+		})
+	})
+
+	return written, err
+}
+
+// copyRows - streams the rows into the table or, to resolve conflicts, into a temporary table
+// they are then inserted from. The latter must run inside a transaction.
+func copyRows(ctx context.Context, c copier, table string, columns []string, values [][]any, conflict string) (int64, error) {
+	if conflict == "" {
+		return c.CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, pgx.CopyFromRows(values))
+	}
+
+	staging := "bulk_insert_staging"
+	list := quoteIdentifiers(columns)
+
+	if _, err := c.Exec(ctx, fmt.Sprintf(`CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP`, quoteIdentifier(staging), quoteIdentifier(table))); err != nil {
+		return 0, err
+	}
+
+	if _, err := c.CopyFrom(ctx, pgx.Identifier{staging}, columns, pgx.CopyFromRows(values)); err != nil {
+		return 0, err
+	}
+
+	tag, err := c.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s%s`, quoteIdentifier(table), list, list, quoteIdentifier(staging), conflict))
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// lastByConflict - keeps one row per value of the conflict columns: the last one, at the position of the first.
+func lastByConflict(values [][]any, columns, conflictColumns []string) [][]any {
+	positions := helpers.Map(conflictColumns, func(_ int, column string) int {
+		return slices.Index(columns, column)
+	})
+
+	if slices.Contains(positions, -1) {
+		// The missing columns take their default value, so the rows cannot be told apart here.
+		return values
+	}
+
+	unique := make([][]any, 0, len(values))
+	index := map[string]int{}
+
+	for _, row := range values {
+		key := fmt.Sprintf("%#v", helpers.Map(positions, func(_ int, position int) any { return row[position] }))
+
+		if i, ok := index[key]; ok {
+			unique[i] = row
+			continue
+		}
+
+		index[key] = len(unique)
+		unique = append(unique, row)
+	}
+
+	return unique
+}
+
+// quoteIdentifier - quotes each part of a (schema-qualified) name, i.e. public.users -> "public"."users".
+func quoteIdentifier(name string) string {
+	return pgx.Identifier(strings.Split(name, ".")).Sanitize()
+}
+
+func quoteIdentifiers(names []string) string {
+	return strings.Join(helpers.Map(names, func(_ int, name string) string { return quoteIdentifier(name) }), ", ")
+}
+
+// valueByPath - returns the value of the field at the index path, or nil if a struct pointer along the way is nil.
+func valueByPath(v reflect.Value, path []int) any {
+	for _, position := range path {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(position)
+	}
+
+	return v.Interface()
+}
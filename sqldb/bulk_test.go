@@ -0,0 +1,357 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func TestBulkInsert(t *testing.T) {
+	type User struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	users := []User{{1, "Ada"}, {2, "Grace"}, {3, "Linus"}}
+	errInsert := errors.New("constraint failed")
+
+	tests := []struct {
+		name    string
+		rows    []User
+		options BulkInsertOptions
+		expect  func(db *sqltest.Backend)
+		want    int64
+		wantErr error
+	}{
+		{
+			name:    "batches sized to the parameter limit",
+			rows:    users,
+			options: BulkInsertOptions{Adapter: SQLite3, Table: "users", MaxParameters: 4},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^`+regexp.QuoteMeta(`INSERT INTO "users" ("id", "name") VALUES (?, ?), (?, ?)`)+`$`).
+					WithArgs(1, "Ada", 2, "Grace").WillReturnResult(0, 2)
+				db.ExpectExec(`^`+regexp.QuoteMeta(`INSERT INTO "users" ("id", "name") VALUES (?, ?)`)+`$`).
+					WithArgs(3, "Linus").WillReturnResult(0, 1)
+			},
+			want: 3,
+		},
+		{
+			name:    "falls back to INSERT without COPY support",
+			rows:    users[:2],
+			options: BulkInsertOptions{Adapter: PostgreSQL, Table: "public.users", Columns: []string{"name"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^`+regexp.QuoteMeta(`INSERT INTO "public"."users" ("name") VALUES ($1), ($2)`)+`$`).
+					WithArgs("Ada", "Grace").WillReturnResult(0, 2)
+			},
+			want: 2,
+		},
+		{
+			name:    "upsert",
+			rows:    users[:1],
+			options: BulkInsertOptions{Adapter: SQLite3, Table: "users", OnConflict: CONFLICT_UPDATE, ConflictColumns: []string{"id"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^`+regexp.QuoteMeta(`INSERT INTO "users" ("id", "name") VALUES (?, ?) ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`)+`$`).
+					WithArgs(1, "Ada").WillReturnResult(0, 1)
+			},
+			want: 1,
+		},
+		{
+			name:    "upsert keeps the last of duplicate rows",
+			rows:    []User{{1, "Ada"}, {2, "Grace"}, {1, "Ada Lovelace"}},
+			options: BulkInsertOptions{Adapter: PostgreSQL, Table: "users", OnConflict: CONFLICT_UPDATE, ConflictColumns: []string{"id"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^`+regexp.QuoteMeta(`INSERT INTO "users" ("id", "name") VALUES ($1, $2), ($3, $4) ON CONFLICT ("id")`)).
+					WithArgs(1, "Ada Lovelace", 2, "Grace").WillReturnResult(0, 2)
+			},
+			want: 2,
+		},
+		{
+			name:    "mixed-case names",
+			rows:    users[:1],
+			options: BulkInsertOptions{Adapter: SQLite3, Table: "Users", Columns: []string{"name"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^`+regexp.QuoteMeta(`INSERT INTO "Users" ("name") VALUES (?)`)+`$`).
+					WithArgs("Ada").WillReturnResult(0, 1)
+			},
+			want: 1,
+		},
+		{
+			name:    "ignored rows are not counted",
+			rows:    users[:2],
+			options: BulkInsertOptions{Adapter: SQLite3, Table: "users", OnConflict: CONFLICT_IGNORE},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(` ON CONFLICT DO NOTHING$`).WillReturnResult(0, 1)
+			},
+			want: 1,
+		},
+		{
+			name:    "failed batch",
+			rows:    users,
+			options: BulkInsertOptions{Adapter: SQLite3, Table: "users", MaxParameters: 4},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^INSERT INTO "users" `).WillReturnResult(0, 2)
+				db.ExpectExec(`^INSERT INTO "users" `).WillReturnError(errInsert)
+			},
+			wantErr: errInsert,
+		},
+		{name: "no rows", options: BulkInsertOptions{Adapter: SQLite3, Table: "users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			if tt.expect != nil {
+				tt.expect(db)
+			}
+
+			got, err := BulkInsert(context.Background(), db, tt.rows, tt.options)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BulkInsert() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("BulkInsert() = %v, want %v", got, tt.want)
+			}
+
+			if tt.wantErr != nil {
+				calls := db.Calls()
+				if last := calls[len(calls)-1]; last.Kind != sqltest.ROLLBACK {
+					t.Errorf("last call = %v, want a rollback", last)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkInsert_invalidOptions(t *testing.T) {
+	type User struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		options BulkInsertOptions
+	}{
+		{name: "unsupported adapter", options: BulkInsertOptions{Adapter: "mysql", Table: "users"}},
+		{name: "invalid table", options: BulkInsertOptions{Adapter: SQLite3, Table: "users; DROP TABLE users"}},
+		{name: "invalid conflict column", options: BulkInsertOptions{Adapter: SQLite3, Table: "users", ConflictColumns: []string{"id)"}}},
+		{
+			name:    "nothing to update",
+			options: BulkInsertOptions{Adapter: SQLite3, Table: "users", OnConflict: CONFLICT_UPDATE, ConflictColumns: []string{"id", "name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+
+			if _, err := BulkInsert(context.Background(), db, []User{{1, "Ada"}}, tt.options); err == nil {
+				t.Errorf("BulkInsert() error = nil, want an error")
+			}
+
+			if calls := db.Calls(); len(calls) != 0 {
+				t.Errorf("BulkInsert() ran %v, want no statements", calls)
+			}
+		})
+	}
+}
+
+func Test_insertColumns(t *testing.T) {
+	type Author struct {
+		Name string `json:"name"`
+	}
+
+	type Post struct {
+		Id     int     `json:"id"`
+		Title  string  `db:"headline"`
+		Body   *string `json:"body"`
+		Author Author  `json:"author"`
+	}
+
+	tests := []struct {
+		name      string
+		model     any
+		selected  []string
+		want      []string
+		wantPaths [][]int
+		wantErr   bool
+	}{
+		{name: "all columns", model: Post{}, want: []string{"id", "headline", "body"}, wantPaths: [][]int{{0}, {1}, {2}}},
+		{name: "pointer type", model: &Post{}, want: []string{"id", "headline", "body"}, wantPaths: [][]int{{0}, {1}, {2}}},
+		{name: "selected columns", model: Post{}, selected: []string{"headline", "id"}, want: []string{"headline", "id"}, wantPaths: [][]int{{1}, {0}}},
+		{name: "nested column", model: Post{}, selected: []string{"author.name"}, wantErr: true},
+		{name: "unknown column", model: Post{}, selected: []string{"title"}, wantErr: true},
+		{name: "not a struct", model: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, paths, err := insertColumns(reflect.TypeOf(tt.model), tt.selected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("insertColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && (!reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(paths, tt.wantPaths)) {
+				t.Errorf("insertColumns() = %v, %v, want %v, %v", got, paths, tt.want, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func Test_conflictClause(t *testing.T) {
+	columns := []string{"id", "email", "name"}
+
+	tests := []struct {
+		name    string
+		options BulkInsertOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "error", options: BulkInsertOptions{}, want: ""},
+		{name: "ignore", options: BulkInsertOptions{OnConflict: CONFLICT_IGNORE}, want: " ON CONFLICT DO NOTHING"},
+		{
+			name:    "update remaining columns",
+			options: BulkInsertOptions{OnConflict: CONFLICT_UPDATE, ConflictColumns: []string{"email"}},
+			want:    ` ON CONFLICT ("email") DO UPDATE SET "id" = excluded."id", "name" = excluded."name"`,
+		},
+		{
+			name:    "update selected columns",
+			options: BulkInsertOptions{OnConflict: CONFLICT_UPDATE, ConflictColumns: []string{"id"}, UpdateColumns: []string{"name"}},
+			want:    ` ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`,
+		},
+		{
+			name:    "update without columns to update",
+			options: BulkInsertOptions{OnConflict: CONFLICT_UPDATE, ConflictColumns: []string{"id", "email", "name"}},
+			wantErr: true,
+		},
+		{name: "update without conflict columns", options: BulkInsertOptions{OnConflict: CONFLICT_UPDATE}, wantErr: true},
+		{name: "unknown action", options: BulkInsertOptions{OnConflict: "merge"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conflictClause(tt.options, columns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("conflictClause() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("conflictClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeCopier struct {
+	statements []string
+	table      pgx.Identifier
+	rows       [][]any
+	err        error
+}
+
+func (c *fakeCopier) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.statements = append(c.statements, sql)
+	return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", len(c.rows))), nil
+}
+
+func (c *fakeCopier) CopyFrom(_ context.Context, table pgx.Identifier, _ []string, rows pgx.CopyFromSource) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	c.table = table
+	for rows.Next() {
+		values, _ := rows.Values()
+		c.rows = append(c.rows, values)
+	}
+
+	return int64(len(c.rows)), rows.Err()
+}
+
+func Test_copyRows(t *testing.T) {
+	values := [][]any{{1, "Ada"}, {2, "Grace"}}
+	errCopy := errors.New("copy failed")
+
+	tests := []struct {
+		name           string
+		conflict       string
+		err            error
+		wantTable      pgx.Identifier
+		wantStatements []string
+		wantErr        error
+	}{
+		{name: "straight into the table", wantTable: pgx.Identifier{"public", "Users"}},
+		{
+			name:      "through a staging table",
+			conflict:  ` ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`,
+			wantTable: pgx.Identifier{"bulk_insert_staging"},
+			wantStatements: []string{
+				`CREATE TEMP TABLE "bulk_insert_staging" (LIKE "public"."Users" INCLUDING DEFAULTS) ON COMMIT DROP`,
+				`INSERT INTO "public"."Users" ("id", "name") SELECT "id", "name" FROM "bulk_insert_staging" ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`,
+			},
+		},
+		{name: "failed copy", conflict: " ON CONFLICT DO NOTHING", err: errCopy, wantErr: errCopy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCopier{err: tt.err}
+
+			got, err := copyRows(context.Background(), c, "public.Users", []string{"id", "name"}, values, tt.conflict)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("copyRows() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got != 2 || !reflect.DeepEqual(c.rows, values) {
+				t.Errorf("copyRows() = %v, copied %v, want 2, %v", got, c.rows, values)
+			}
+
+			if !reflect.DeepEqual(c.table, tt.wantTable) || !reflect.DeepEqual(c.statements, tt.wantStatements) {
+				t.Errorf("copyRows() copied into %v and ran %q, want %v and %q", c.table, c.statements, tt.wantTable, tt.wantStatements)
+			}
+		})
+	}
+}
+
+func Test_lastByConflict(t *testing.T) {
+	columns := []string{"tenant", "id", "name"}
+	values := [][]any{{"a", 1, "Ada"}, {"b", 1, "Grace"}, {"a", 1, "Ada Lovelace"}, {"a", 2, "Linus"}}
+
+	tests := []struct {
+		name            string
+		conflictColumns []string
+		want            [][]any
+	}{
+		{
+			name:            "single column",
+			conflictColumns: []string{"id"},
+			want:            [][]any{{"a", 1, "Ada Lovelace"}, {"a", 2, "Linus"}},
+		},
+		{
+			name:            "composite key",
+			conflictColumns: []string{"tenant", "id"},
+			want:            [][]any{{"a", 1, "Ada Lovelace"}, {"b", 1, "Grace"}, {"a", 2, "Linus"}},
+		},
+		{name: "column not inserted", conflictColumns: []string{"email"}, want: values},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastByConflict(values, columns, tt.conflictColumns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lastByConflict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DEFAULT_HEALTH_CHECK_INTERVAL = 5 * time.Second
+	DEFAULT_HEALTH_CHECK_TIMEOUT  = time.Second
+)
+
+type (
+	// Cluster - SqlBackend sending writes and transactions to a primary database and spreading reads across its replicas.
+	Cluster struct {
+		primary  SqlBackend
+		replicas []*replica
+		next     atomic.Uint64
+
+		stop chan struct{}
+		once sync.Once
+		wg   sync.WaitGroup
+	}
+
+	ClusterOptions struct {
+		// How often replicas are pinged. Unreachable replicas receive no reads until they answer again.
+		// Defaults to DEFAULT_HEALTH_CHECK_INTERVAL.
+		HealthCheckInterval time.Duration
+
+		// How long a replica has to answer a ping. Defaults to DEFAULT_HEALTH_CHECK_TIMEOUT.
+		HealthCheckTimeout time.Duration
+	}
+
+	replica struct {
+		db      SqlBackend
+		healthy atomic.Bool
+	}
+
+	pinger interface {
+		PingContext(ctx context.Context) error
+	}
+
+	primaryContextKey struct{}
+)
+
+// NewCluster - returns a Cluster over the primary and replicas, and starts checking the health of the replicas.
+//
+// QueryContext and QueryRowContext are routed to the healthy replicas in turn, or to the primary when
+// none is healthy or the context was marked with WithPrimary. ExecContext, BeginTx, Conn and PingContext
+// always use the primary, so session-level features such as advisory locks and COPY work on a Cluster.
+//
+// Usage:
+//
+//	cluster := NewCluster(primary, []SqlBackend{replica1, replica2}, ClusterOptions{})
+//	defer cluster.Close()
+//
+//	rows, err := cluster.QueryContext(ctx, `SELECT * FROM users`) // replica1
+//	rows, err = cluster.QueryContext(WithPrimary(ctx), `SELECT * FROM users`) // primary
+func NewCluster(primary SqlBackend, replicas []SqlBackend, options ClusterOptions) *Cluster {
+	if options.HealthCheckInterval <= 0 {
+		options.HealthCheckInterval = DEFAULT_HEALTH_CHECK_INTERVAL
+	}
+
+	if options.HealthCheckTimeout <= 0 {
+		options.HealthCheckTimeout = DEFAULT_HEALTH_CHECK_TIMEOUT
+	}
+
+	c := &Cluster{primary: primary, stop: make(chan struct{})}
+
+	for _, db := range replicas {
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+
+	if len(c.replicas) > 0 {
+		c.wg.Add(1)
+		go c.monitor(options)
+	}
+
+	return c
+}
+
+// WithPrimary - returns a context whose reads are sent to the primary, i.e. to read data
+// just written without waiting for it to reach the replicas.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// Primary - returns the primary database.
+func (c *Cluster) Primary() SqlBackend { return c.primary }
+
+// Replicas - returns the replica databases, healthy or not.
+func (c *Cluster) Replicas() []SqlBackend {
+	replicas := make([]SqlBackend, len(c.replicas))
+	for i, r := range c.replicas {
+		replicas[i] = r.db
+	}
+
+	return replicas
+}
+
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.reader(ctx).QueryContext(ctx, query, args...)
+}
+
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.reader(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (c *Cluster) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.primary.BeginTx(ctx, opts)
+}
+
+// Conn - returns a dedicated connection to the primary. It fails with errors.ErrUnsupported
+// if the primary cannot hand out connections.
+func (c *Cluster) Conn(ctx context.Context) (*sql.Conn, error) {
+	pool, ok := c.primary.(connector)
+	if !ok {
+		return nil, fmt.Errorf("primary database cannot hand out dedicated connections: %w", errors.ErrUnsupported)
+	}
+
+	return pool.Conn(ctx)
+}
+
+// PingContext - checks the primary is reachable. Replicas are checked in the background.
+func (c *Cluster) PingContext(ctx context.Context) error {
+	if p, ok := c.primary.(pinger); ok {
+		return p.PingContext(ctx)
+	}
+
+	_, err := c.primary.ExecContext(ctx, `SELECT 1`)
+	return err
+}
+
+// Close - stops the health checks and closes every database of the cluster.
+func (c *Cluster) Close() error {
+	c.once.Do(func() { close(c.stop) })
+	c.wg.Wait()
+
+	var errs []error
+	for _, db := range append([]SqlBackend{c.primary}, c.Replicas()...) {
+		if closer, ok := db.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reader - returns the next healthy replica, or the primary.
+func (c *Cluster) reader(ctx context.Context) SqlBackend {
+	if primary, _ := ctx.Value(primaryContextKey{}).(bool); primary || len(c.replicas) == 0 {
+		return c.primary
+	}
+
+	// Reads inside a transaction started by WithTx must see its writes.
+	if _, inTx := TxFromContext(ctx); inTx {
+		return c.primary
+	}
+
+	start := c.next.Add(1) - 1
+	for i := range c.replicas {
+		r := c.replicas[(start+uint64(i))%uint64(len(c.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return c.primary
+}
+
+func (c *Cluster) monitor(options ClusterOptions) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(options.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		c.check(options.HealthCheckTimeout)
+
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check - pings every replica concurrently and records which ones answered.
+func (c *Cluster) check(timeout time.Duration) {
+	var wg sync.WaitGroup
+
+	for _, r := range c.replicas {
+		p, ok := r.db.(pinger)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(r *replica) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			r.healthy.Store(p.PingContext(ctx) == nil)
+		}(r)
+	}
+
+	wg.Wait()
+}
+
+var _ SqlBackend = (*Cluster)(nil)
@@ -0,0 +1,163 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+type unreachable struct{ SqlBackend }
+
+func (unreachable) PingContext(context.Context) error { return errors.New("connection refused") }
+
+// dedicated - counts the connections handed out by the backend.
+type dedicated struct {
+	*sqltest.Backend
+	conns int
+}
+
+func (d *dedicated) Conn(ctx context.Context) (*sql.Conn, error) {
+	d.conns++
+	return d.Backend.Conn(ctx)
+}
+
+func TestCluster_routing(t *testing.T) {
+	primary, first, second := sqltest.New(t), sqltest.New(t), sqltest.New(t)
+
+	primary.ExpectExec(`^UPDATE users`)
+	primary.ExpectQuery(`^SELECT name FROM users$`).WillReturnRows([]string{"name"}, []any{"primary"}).Times(2)
+	first.ExpectQuery(`^SELECT name FROM users$`).WillReturnRows([]string{"name"}, []any{"first"}).Times(2)
+	second.ExpectQuery(`^SELECT name FROM users$`).WillReturnRows([]string{"name"}, []any{"second"})
+
+	cluster := NewCluster(primary, []SqlBackend{first, second}, ClusterOptions{})
+	defer cluster.Close()
+
+	ctx := context.Background()
+
+	if _, err := cluster.ExecContext(ctx, `UPDATE users SET name = 'alice'`); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	read := func(ctx context.Context) string {
+		var name string
+		if err := cluster.QueryRowContext(ctx, `SELECT name FROM users`).Scan(&name); err != nil {
+			t.Fatalf("QueryRowContext() error = %v", err)
+		}
+
+		return name
+	}
+
+	got := []string{read(ctx), read(ctx), read(ctx), read(WithPrimary(ctx))}
+
+	err := WithTx(ctx, cluster, nil, func(ctx context.Context, _ *sql.Tx) error {
+		got = append(got, read(ctx))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+
+	want := []string{"first", "second", "first", "primary", "primary"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("reads = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestCluster_healthChecks(t *testing.T) {
+	primary, replica := sqltest.New(t), sqltest.New(t)
+
+	cluster := NewCluster(primary, []SqlBackend{unreachable{replica}}, ClusterOptions{HealthCheckInterval: time.Hour})
+	cluster.check(time.Second)
+
+	if got := cluster.reader(context.Background()); got != primary {
+		t.Errorf("reader() = %v, want the primary while the replica is unreachable", got)
+	}
+
+	if err := cluster.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestCluster_Conn(t *testing.T) {
+	primary, replica := &dedicated{Backend: sqltest.New(t)}, sqltest.New(t)
+
+	primary.ExpectExec(`^SET lock_timeout`)
+	primary.ExpectQuery(`^SELECT 1$`).WillReturnRows([]string{"one"}, []any{int64(1)})
+
+	cluster := NewCluster(primary, []SqlBackend{replica}, ClusterOptions{})
+	defer cluster.Close()
+
+	ctx := context.Background()
+
+	conn, err := cluster.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SET lock_timeout = '1s'`); err != nil {
+		t.Errorf("ExecContext() error = %v", err)
+	}
+
+	// Reads on a dedicated connection stay on the primary.
+	var one int
+	if err := conn.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+		t.Errorf("QueryRowContext() error = %v", err)
+	}
+
+	if err := cluster.PingContext(ctx); err != nil {
+		t.Errorf("PingContext() error = %v", err)
+	}
+
+	if primary.conns != 1 {
+		t.Errorf("Conn() took %d connections from the primary, want 1", primary.conns)
+	}
+
+	withoutConn := NewCluster(struct{ SqlBackend }{primary}, nil, ClusterOptions{})
+	if _, err := withoutConn.Conn(ctx); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Conn() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+}
+
+func TestCluster_bulkInsert(t *testing.T) {
+	type User struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name      string
+		primary   func(db *dedicated) SqlBackend
+		wantConns int
+	}{
+		// The fake driver cannot COPY, so rows are inserted once COPY is found to be unsupported.
+		{name: "copy attempted on the primary", primary: func(db *dedicated) SqlBackend { return db }, wantConns: 1},
+		{name: "primary without connections", primary: func(db *dedicated) SqlBackend { return struct{ SqlBackend }{db} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, replica := &dedicated{Backend: sqltest.New(t)}, sqltest.New(t)
+			primary.ExpectExec(`^INSERT INTO "users" \("id", "name"\) VALUES \(\$1, \$2\)$`).WithArgs(1, "Ada").WillReturnResult(0, 1)
+
+			cluster := NewCluster(tt.primary(primary), []SqlBackend{replica}, ClusterOptions{})
+			defer cluster.Close()
+
+			written, err := BulkInsert(context.Background(), cluster, []User{{1, "Ada"}}, BulkInsertOptions{Adapter: PostgreSQL, Table: "users"})
+			if err != nil || written != 1 {
+				t.Fatalf("BulkInsert() = %v, %v, want 1, nil", written, err)
+			}
+
+			if primary.conns != tt.wantConns {
+				t.Errorf("BulkInsert() took %d connections from the primary, want %d", primary.conns, tt.wantConns)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+type SchemaFormat string
+
+const (
+	SCHEMA_FORMAT_SQL  SchemaFormat = "sql"
+	SCHEMA_FORMAT_YAML SchemaFormat = "yaml"
+
+	// Number of unchanged lines shown around each change in a drift report.
+	driftContextLines = 2
+)
+
+var ErrSchemaDrift = errors.New("schema drift detected")
+
+// SchemaFormatOf - returns the dump format matching the extension of the file.
+func SchemaFormatOf(path string) (SchemaFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sql":
+		return SCHEMA_FORMAT_SQL, nil
+	case ".yaml", ".yml":
+		return SCHEMA_FORMAT_YAML, nil
+	}
+
+	return "", fmt.Errorf("unsupported schema file extension: %q", path)
+}
+
+// Dump - renders the schema in the given format.
+//
+// Dumps are canonical: the same schema always renders the same bytes, so they can be
+// checked in and compared with CompareSchemaDump.
+func (s Schema) Dump(format SchemaFormat) ([]byte, error) {
+	switch format {
+	case SCHEMA_FORMAT_SQL:
+		return []byte(s.SQL()), nil
+	case SCHEMA_FORMAT_YAML:
+		return yaml.Marshal(s)
+	}
+
+	return nil, fmt.Errorf("unsupported schema format: %q", format)
+}
+
+// SQL - renders the schema as CREATE TABLE and CREATE INDEX statements.
+//
+// Usage:
+//
+//	schema, _ := Inspect(ctx, db, SQLite3)
+//	fmt.Println(schema.SQL())
+//
+//	// CREATE TABLE users (
+//	// 	id INTEGER,
+//	// 	email TEXT NOT NULL,
+//	// 	PRIMARY KEY (id)
+//	// );
+func (s Schema) SQL() string {
+	statements := helpers.Map(s.Tables, func(_ int, t Table) string { return t.SQL() })
+	return strings.Join(statements, "\n\n") + "\n"
+}
+
+// SQL - renders the table and its indexes. Indexes backing unique constraints are rendered as constraints.
+func (t Table) SQL() string {
+	definitions := []string{}
+	constrained := map[string]bool{}
+
+	for _, column := range t.Columns {
+		definition := column.Name + " " + column.Type
+		if !column.Nullable {
+			definition += " NOT NULL"
+		}
+
+		if column.Default != nil {
+			definition += " DEFAULT " + *column.Default
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	for _, constraint := range t.Constraints {
+		constrained[constraint.Name] = true
+
+		definition := constraint.Definition
+		if definition == "" {
+			definition = fmt.Sprintf("%s (%s)", constraint.Type, strings.Join(constraint.Columns, ", "))
+		}
+
+		definitions = append(definitions, constraintName(constraint.Name)+definition)
+	}
+
+	for _, fk := range t.ForeignKeys {
+		definition := fmt.Sprintf("%sFOREIGN KEY (%s) REFERENCES %s", constraintName(fk.Name), strings.Join(fk.Columns, ", "), fk.ReferencedTable)
+
+		if len(fk.ReferencedColumns) > 0 {
+			definition += " (" + strings.Join(fk.ReferencedColumns, ", ") + ")"
+		}
+
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			definition += " ON UPDATE " + fk.OnUpdate
+		}
+
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			definition += " ON DELETE " + fk.OnDelete
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n\t%s\n);", t.Name, strings.Join(definitions, ",\n\t"))
+
+	for _, index := range t.Indexes {
+		if constrained[index.Name] {
+			continue
+		}
+
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+
+		fmt.Fprintf(&b, "\nCREATE %sINDEX %s ON %s (%s);", unique, index.Name, t.Name, strings.Join(index.Columns, ", "))
+	}
+
+	return b.String()
+}
+
+// constraintName - returns the CONSTRAINT clause for user-defined names. SQLite's generated names are omitted.
+func constraintName(name string) string {
+	if name == "" || strings.HasPrefix(name, "sqlite_") {
+		return ""
+	}
+
+	return "CONSTRAINT " + name + " "
+}
+
+// CompareSchemaDump - returns an error wrapping ErrSchemaDrift, followed by a line diff, if the dumps differ.
+// Lines only found in the snapshot are prefixed with "-", and lines only found in the current dump with "+".
+//
+// Usage:
+//
+//	snapshot, _ := os.ReadFile("db/schema.sql")
+//	schema, _ := Inspect(ctx, db, PostgreSQL)
+//	current, _ := schema.Dump(SCHEMA_FORMAT_SQL)
+//
+//	if err := CompareSchemaDump(snapshot, current); err != nil {
+//		log.Fatal(err)
+//	}
+func CompareSchemaDump(snapshot, current []byte) error {
+	expected := strings.Split(strings.TrimRight(strings.ReplaceAll(string(snapshot), "\r\n", "\n"), "\n"), "\n")
+	actual := strings.Split(strings.TrimRight(string(current), "\n"), "\n")
+
+	diff := diffLines(expected, actual)
+	if len(diff) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w:\n%s", ErrSchemaDrift, strings.Join(diff, "\n"))
+}
+
+// diffLines - returns the changed lines between a and b, surrounded by a few unchanged lines of context.
+// It returns nothing if both are equal.
+func diffLines(a, b []string) []string {
+	// Common prefixes and suffixes are trimmed first, so the LCS table only spans the changed region.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	if prefix == len(a) && prefix == len(b) {
+		return nil
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+
+	lines := []line{}
+	for _, text := range a[:prefix] {
+		lines = append(lines, line{' ', text})
+	}
+
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, line{' ', x[i]})
+			i, j = i+1, j+1
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', x[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', y[j]})
+			j++
+		}
+	}
+
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, line{' ', text})
+	}
+
+	// Keep the changed lines and their context, marking skipped regions with "...".
+	visible := make([]bool, len(lines))
+	for index, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+
+		for k := max(0, index-driftContextLines); k <= min(len(lines)-1, index+driftContextLines); k++ {
+			visible[k] = true
+		}
+	}
+
+	diff := []string{}
+	for index, l := range lines {
+		if !visible[index] {
+			if index > 0 && visible[index-1] {
+				diff = append(diff, "...")
+			}
+
+			continue
+		}
+
+		if index > 0 && !visible[index-1] && len(diff) == 0 {
+			diff = append(diff, "...")
+		}
+
+		diff = append(diff, string(l.op)+" "+l.text)
+	}
+
+	return diff
+}
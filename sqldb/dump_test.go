@@ -0,0 +1,85 @@
+package sqldb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+)
+
+func TestTable_SQL(t *testing.T) {
+	table := Table{
+		Name: "posts",
+		Columns: []Column{
+			{Name: "id", Type: "bigint", Default: helpers.PointerTo("nextval('posts_id_seq'::regclass)")},
+			{Name: "slug", Type: "text"},
+			{Name: "author_id", Type: "bigint", Nullable: true},
+		},
+		Indexes: []Index{
+			{Name: "posts_author_id_idx", Columns: []string{"author_id"}},
+			{Name: "posts_slug_key", Columns: []string{"slug"}, Unique: true},
+		},
+		ForeignKeys: []ForeignKey{
+			{Name: "posts_author_id_fkey", Columns: []string{"author_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+		},
+		Constraints: []Constraint{
+			{Name: "posts_pkey", Type: CONSTRAINT_PRIMARY_KEY, Columns: []string{"id"}, Definition: "PRIMARY KEY (id)"},
+			{Name: "posts_slug_key", Type: CONSTRAINT_UNIQUE, Columns: []string{"slug"}},
+		},
+	}
+
+	want := `CREATE TABLE posts (
+	id bigint NOT NULL DEFAULT nextval('posts_id_seq'::regclass),
+	slug text NOT NULL,
+	author_id bigint,
+	CONSTRAINT posts_pkey PRIMARY KEY (id),
+	CONSTRAINT posts_slug_key UNIQUE (slug),
+	CONSTRAINT posts_author_id_fkey FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX posts_author_id_idx ON posts (author_id);`
+
+	if got := table.SQL(); got != want {
+		t.Errorf("SQL() = %v, want %v", got, want)
+	}
+}
+
+func TestCompareSchemaDump(t *testing.T) {
+	snapshot := []byte("a\nb\nc\nd\ne\nf\ng\n")
+
+	if err := CompareSchemaDump([]byte("a\r\nb\r\nc\r\nd\r\ne\r\nf\r\ng\r\n"), snapshot); err != nil {
+		t.Errorf("CompareSchemaDump() error = %v, want nil", err)
+	}
+
+	err := CompareSchemaDump(snapshot, []byte("a\nb\nc\nD\ne\nf\ng\n"))
+	if !errors.Is(err, ErrSchemaDrift) {
+		t.Fatalf("CompareSchemaDump() error = %v, want %v", err, ErrSchemaDrift)
+	}
+
+	want := "schema drift detected:\n...\n  b\n  c\n- d\n+ D\n  e\n  f\n..."
+	if err.Error() != want {
+		t.Errorf("CompareSchemaDump() error = %q, want %q", err.Error(), want)
+	}
+}
+
+func Test_diffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []string
+		b    []string
+		want []string
+	}{
+		{name: "equal", a: []string{"a", "b"}, b: []string{"a", "b"}, want: nil},
+		{name: "added", a: []string{"a"}, b: []string{"a", "b"}, want: []string{"  a", "+ b"}},
+		{name: "removed", a: []string{"a", "b", "c"}, b: []string{"a", "c"}, want: []string{"  a", "- b", "  c"}},
+		{name: "replaced", a: []string{"x"}, b: []string{"y"}, want: []string{"- x", "+ y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
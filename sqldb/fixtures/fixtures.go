@@ -0,0 +1,414 @@
+// Package fixtures seeds databases with rows described in YAML or JSON files.
+//
+// Each file describes the rows of the table it is named after, keyed by a label:
+//
+//	# users.yml
+//	alice:
+//	  name: Alice
+//	  email: alice@example.com
+//
+//	# posts.yml
+//	hello_world:
+//	  title: Hello, World!
+//	  author_id: users.alice
+//
+// String values of the form `<table>.<label>` that name another fixture are references,
+// and are replaced with the primary key of the fixture they name. Referenced fixtures without
+// an explicit primary key are given one derived from their label, so ids are stable across runs.
+//
+// Any string equal to the `<table>.<label>` of a fixture in the set is a reference, wherever it
+// appears as a top-level value. Such a string cannot be stored as is; values inside lists and
+// maps are never resolved.
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/query"
+	"gopkg.in/yaml.v3"
+)
+
+const DEFAULT_PRIMARY_KEY = "id"
+
+var (
+	ErrFixtureNotFound = errors.New("fixture not found")
+	ErrCircularFixture = errors.New("circular fixture references")
+
+	// Unlike sqldb.IdentifierValidationPattern, schema-qualified names are rejected: tables are named
+	// after their files, and a dot in the table would make `<table>.<label>` references ambiguous.
+	identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type (
+	Fixture struct {
+		Table  string
+		Label  string
+		Values map[string]any
+	}
+
+	// Set - fixtures sorted so that every fixture comes after the fixtures it references.
+	Set struct {
+		fixtures   []*Fixture
+		index      map[string]*Fixture
+		references map[string][]string
+	}
+)
+
+// Load - reads every `.yml`, `.yaml` and `.json` file at the root of `source`.
+//
+// Usage:
+//
+//	set, err := fixtures.Load(os.DirFS("testdata/fixtures"))
+//	err = set.Reset(ctx, db, sqldb.SQLite3)
+func Load(source fs.FS) (*Set, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := []*Fixture{}
+
+	for _, entry := range entries {
+		extension := path.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".yml" && extension != ".yaml" && extension != ".json") {
+			continue
+		}
+
+		table := strings.TrimSuffix(entry.Name(), extension)
+		if !identifierPattern.MatchString(table) {
+			return nil, fmt.Errorf("invalid table name in fixture file: %q", entry.Name())
+		}
+
+		data, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		// JSON is valid YAML, so both formats share the same decoder.
+		rows := map[string]map[string]any{}
+		if err := yaml.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		for label, values := range rows {
+			if values == nil {
+				values = map[string]any{}
+			}
+
+			fixtures = append(fixtures, &Fixture{Table: table, Label: label, Values: values})
+		}
+	}
+
+	return New(fixtures...)
+}
+
+// New - resolves the references between the fixtures and sorts them in dependency order.
+//
+// The set holds copies of the fixtures, so the given values are left untouched.
+func New(fixtures ...*Fixture) (*Set, error) {
+	s := &Set{index: map[string]*Fixture{}, references: map[string][]string{}}
+
+	fixtures = helpers.Map(fixtures, func(_ int, f *Fixture) *Fixture {
+		values := make(map[string]any, len(f.Values)+1)
+		for column, value := range f.Values {
+			values[column] = value
+		}
+
+		return &Fixture{Table: f.Table, Label: f.Label, Values: values}
+	})
+
+	for _, f := range fixtures {
+		if s.index[f.key()] != nil {
+			return nil, fmt.Errorf("duplicate fixture: %s", f.key())
+		}
+
+		for column := range f.Values {
+			if !identifierPattern.MatchString(column) {
+				return nil, fmt.Errorf("invalid column name in fixture %s: %q", f.key(), column)
+			}
+		}
+
+		s.index[f.key()] = f
+	}
+
+	// Fixtures are visited in a stable order so that insertion order does not depend on map iteration.
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].key() < fixtures[j].key() })
+
+	dependencies := map[string][]*Fixture{}
+	for _, f := range fixtures {
+		for column, value := range f.Values {
+			target := s.reference(value)
+			if target == nil {
+				continue
+			}
+
+			if _, ok := target.Values[DEFAULT_PRIMARY_KEY]; !ok {
+				target.Values[DEFAULT_PRIMARY_KEY] = Identify(target.Label)
+			}
+
+			f.Values[column] = target
+			dependencies[f.key()] = append(dependencies[f.key()], target)
+
+			if target.Table != f.Table && !helpers.Contains(s.references[f.Table], target.Table) {
+				s.references[f.Table] = append(s.references[f.Table], target.Table)
+			}
+		}
+	}
+
+	for _, targets := range dependencies {
+		sort.Slice(targets, func(i, j int) bool { return targets[i].key() < targets[j].key() })
+	}
+
+	for _, tables := range s.references {
+		sort.Strings(tables)
+	}
+
+	state := map[string]int{}
+	const visiting, visited = 1, 2
+
+	var visit func(f *Fixture) error
+	visit = func(f *Fixture) error {
+		switch state[f.key()] {
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrCircularFixture, f.key())
+		case visited:
+			return nil
+		}
+
+		state[f.key()] = visiting
+
+		for _, dependency := range dependencies[f.key()] {
+			if dependency != f {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[f.key()] = visited
+		s.fixtures = append(s.fixtures, f)
+
+		return nil
+	}
+
+	for _, f := range fixtures {
+		if err := visit(f); err != nil {
+			return nil, err
+		}
+	}
+
+	// References are replaced with primary keys once every referenced fixture has been assigned one.
+	for _, f := range fixtures {
+		for column, value := range f.Values {
+			if target, ok := value.(*Fixture); ok {
+				f.Values[column] = target.Values[DEFAULT_PRIMARY_KEY]
+			}
+		}
+	}
+
+	// Generated ids may collide with each other or with explicit ones, which would otherwise only
+	// surface as a primary key violation on insert.
+	ids := map[string]*Fixture{}
+	for _, f := range fixtures {
+		id, ok := f.Values[DEFAULT_PRIMARY_KEY]
+		if !ok {
+			continue
+		}
+
+		key := fmt.Sprintf("%s.%v", f.Table, id)
+		if other := ids[key]; other != nil {
+			return nil, fmt.Errorf("fixtures %s and %s have the same %s: %v", other.key(), f.key(), DEFAULT_PRIMARY_KEY, id)
+		}
+
+		ids[key] = f
+	}
+
+	return s, nil
+}
+
+// Identify - returns the primary key generated for a fixture with the given label.
+//
+// Ids are kept below 2^30 so they fit in 32-bit integer columns. Distinct labels may therefore
+// share an id, in which case New reports both fixtures; give one of them an explicit id.
+func Identify(label string) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(label))
+	return int64(h.Sum32() % (1 << 30))
+}
+
+// Get - returns the fixture with the given table and label.
+func (s *Set) Get(table, label string) (*Fixture, error) {
+	f, ok := s.index[table+"."+label]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s.%s", ErrFixtureNotFound, table, label)
+	}
+
+	return f, nil
+}
+
+// Fixtures - returns the fixtures in insertion order.
+func (s *Set) Fixtures() []*Fixture {
+	return append([]*Fixture{}, s.fixtures...)
+}
+
+// Tables - returns the tables with fixtures, each listed after the tables it references.
+//
+// Tables referencing each other, even through different fixtures, cannot all be listed after the
+// tables they reference, so Truncate may fail on SQLite when foreign keys are enforced.
+func (s *Set) Tables() []string {
+	tables := []string{}
+	seen := map[string]bool{}
+
+	var visit func(table string)
+	visit = func(table string) {
+		if seen[table] {
+			return
+		}
+
+		seen[table] = true
+
+		for _, reference := range s.references[table] {
+			visit(reference)
+		}
+
+		tables = append(tables, table)
+	}
+
+	for _, f := range s.fixtures {
+		visit(f.Table)
+	}
+
+	return tables
+}
+
+// Insert - inserts every fixture inside a single transaction.
+func (s *Set) Insert(ctx context.Context, db sqldb.SqlBackend, adapter sqldb.SQLAdapter) error {
+	return sqldb.WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+		for _, f := range s.fixtures {
+			if err := f.insert(ctx, tx, adapter); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Truncate - deletes every row of the tables with fixtures.
+//
+// On Postgres, tables are truncated with RESTART IDENTITY CASCADE, which also empties
+// tables referencing them. On SQLite, rows are deleted in reverse dependency order.
+func (s *Set) Truncate(ctx context.Context, db sqldb.SqlBackend, adapter sqldb.SQLAdapter) error {
+	return sqldb.WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+		return s.truncate(ctx, tx, adapter)
+	})
+}
+
+// Reset - truncates the tables and inserts the fixtures again inside a single transaction.
+//
+// Usage:
+//
+//	func TestMain(m *testing.M) {
+//		// ...
+//		if err := set.Reset(ctx, db, sqldb.SQLite3); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func (s *Set) Reset(ctx context.Context, db sqldb.SqlBackend, adapter sqldb.SQLAdapter) error {
+	return sqldb.WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+		if err := s.truncate(ctx, tx, adapter); err != nil {
+			return err
+		}
+
+		// Nested calls run in the same transaction.
+		return s.Insert(ctx, db, adapter)
+	})
+}
+
+func (s *Set) truncate(ctx context.Context, tx *sql.Tx, adapter sqldb.SQLAdapter) error {
+	tables := s.Tables()
+	if len(tables) == 0 {
+		return nil
+	}
+
+	switch adapter {
+	case sqldb.PostgreSQL:
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", strings.Join(tables, ", ")))
+		return err
+	case sqldb.SQLite3:
+		for i := len(tables) - 1; i >= 0; i-- {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+tables[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unsupported adapter: %q", adapter)
+}
+
+// reference - returns the fixture named by the value, if it is a `<table>.<label>` string naming one.
+func (s *Set) reference(value any) *Fixture {
+	name, ok := value.(string)
+	if !ok {
+		return nil
+	}
+
+	return s.index[name]
+}
+
+func (f *Fixture) key() string { return f.Table + "." + f.Label }
+
+func (f *Fixture) insert(ctx context.Context, tx *sql.Tx, adapter sqldb.SQLAdapter) error {
+	columns := make([]string, 0, len(f.Values))
+	for column := range f.Values {
+		columns = append(columns, column)
+	}
+
+	sort.Strings(columns)
+
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		value, err := columnValue(f.Values[column])
+		if err != nil {
+			return fmt.Errorf("fixture %s: %w", f.key(), err)
+		}
+
+		values[i] = value
+	}
+
+	statement, args, err := query.Insert(f.Table).Columns(columns...).Values(values...).Build(adapter)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, statement, args...); err != nil {
+		return fmt.Errorf("fixture %s: %w", f.key(), err)
+	}
+
+	return nil
+}
+
+// columnValue - encodes lists and maps as JSON, so they can be stored in json/jsonb or text columns.
+func columnValue(value any) (any, error) {
+	switch value.(type) {
+	case []any, map[string]any:
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+
+	return value, nil
+}
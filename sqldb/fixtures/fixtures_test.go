@@ -0,0 +1,213 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func TestLoad(t *testing.T) {
+	set, err := Load(fstest.MapFS{
+		"users.yml":    {Data: []byte("alice:\n  name: Alice\nbob:\n  id: 7\n  name: Bob\n")},
+		"posts.json":   {Data: []byte(`{"hello": {"title": "Hello", "author_id": "users.alice", "editor_id": "users.bob"}}`)},
+		"comments.yml": {Data: []byte("first:\n  body: users.carol\n  post_id: posts.hello\n")},
+		"README.md":    {Data: []byte("ignored")},
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got, want := set.Tables(), []string{"users", "posts", "comments"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tables() = %v, want %v", got, want)
+	}
+
+	comment, _ := set.Get("comments", "first")
+	want := map[string]any{"body": "users.carol", "post_id": Identify("hello")}
+
+	if !reflect.DeepEqual(comment.Values, want) {
+		t.Errorf("Get() = %v, want %v", comment.Values, want)
+	}
+
+	post, _ := set.Get("posts", "hello")
+	if post.Values["author_id"] != Identify("alice") || post.Values["editor_id"] != 7 {
+		t.Errorf("Get() = %v, want references resolved to primary keys", post.Values)
+	}
+
+	if _, err := set.Get("posts", "missing"); !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrFixtureNotFound)
+	}
+}
+
+func TestNew_circular(t *testing.T) {
+	_, err := New(
+		&Fixture{Table: "a", Label: "one", Values: map[string]any{"b_id": "b.one"}},
+		&Fixture{Table: "b", Label: "one", Values: map[string]any{"a_id": "a.one"}},
+	)
+
+	if !errors.Is(err, ErrCircularFixture) {
+		t.Errorf("New() error = %v, want %v", err, ErrCircularFixture)
+	}
+}
+
+func TestNew_duplicateIds(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []*Fixture
+		wantErr  bool
+	}{
+		{
+			// Both labels hash to the same generated id.
+			name: "generated ids",
+			fixtures: []*Fixture{
+				{Table: "users", Label: "user_398824", Values: map[string]any{}},
+				{Table: "users", Label: "user_768140", Values: map[string]any{}},
+				{Table: "posts", Label: "one", Values: map[string]any{"a": "users.user_398824", "b": "users.user_768140"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "explicit ids",
+			fixtures: []*Fixture{
+				{Table: "users", Label: "alice", Values: map[string]any{"id": 1}},
+				{Table: "users", Label: "bob", Values: map[string]any{"id": 1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "same id in different tables",
+			fixtures: []*Fixture{
+				{Table: "users", Label: "alice", Values: map[string]any{"id": 1}},
+				{Table: "posts", Label: "hello", Values: map[string]any{"id": 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.fixtures...); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_copiesValues(t *testing.T) {
+	post := &Fixture{Table: "posts", Label: "hello", Values: map[string]any{"author_id": "users.alice"}}
+	user := &Fixture{Table: "users", Label: "alice", Values: map[string]any{"name": "Alice"}}
+
+	set, err := New(post, user)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if want := map[string]any{"author_id": "users.alice"}; !reflect.DeepEqual(post.Values, want) {
+		t.Errorf("New() changed the fixture values to %v, want %v", post.Values, want)
+	}
+
+	if want := map[string]any{"name": "Alice"}; !reflect.DeepEqual(user.Values, want) {
+		t.Errorf("New() changed the fixture values to %v, want %v", user.Values, want)
+	}
+
+	got, _ := set.Get("posts", "hello")
+	if got == post || got.Values["author_id"] != Identify("alice") {
+		t.Errorf("Get() = %v, want a copy with resolved references", got.Values)
+	}
+
+	if _, err := New(&Fixture{Table: "users", Label: "bob"}); err != nil {
+		t.Errorf("New() error = %v, want fixtures without values to be accepted", err)
+	}
+}
+
+func TestSet_Reset(t *testing.T) {
+	set, err := New(
+		&Fixture{Table: "posts", Label: "hello", Values: map[string]any{"author_id": "users.alice", "tags": []any{"go"}}},
+		&Fixture{Table: "users", Label: "alice", Values: map[string]any{"name": "Alice"}},
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	db := sqltest.New(t)
+	db.ExpectExec(`^DELETE FROM posts$`)
+	db.ExpectExec(`^DELETE FROM users$`)
+	db.ExpectExec(`^SAVEPOINT`)
+	db.ExpectExec(`^INSERT INTO users \(id, name\)`).WithArgs(Identify("alice"), "Alice")
+	db.ExpectExec(`^INSERT INTO posts \(author_id, tags\)`).WithArgs(Identify("alice"), `["go"]`)
+	db.ExpectExec(`^RELEASE SAVEPOINT`)
+
+	if err := set.Reset(context.Background(), db, sqldb.SQLite3); err != nil {
+		t.Errorf("Reset() error = %v", err)
+	}
+}
+
+func TestSet_Tables(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []*Fixture
+		want     []string
+	}{
+		{
+			name: "referenced table inserted later",
+			fixtures: []*Fixture{
+				{Table: "a", Label: "a1", Values: map[string]any{}},
+				{Table: "a", Label: "a2", Values: map[string]any{"b_id": "b.b1"}},
+				{Table: "b", Label: "b1", Values: map[string]any{}},
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "self reference",
+			fixtures: []*Fixture{
+				{Table: "users", Label: "alice", Values: map[string]any{"manager_id": "users.bob"}},
+				{Table: "users", Label: "bob", Values: map[string]any{}},
+			},
+			want: []string{"users"},
+		},
+		{
+			name: "tables referencing each other",
+			fixtures: []*Fixture{
+				{Table: "a", Label: "a1", Values: map[string]any{"b_id": "b.b1"}},
+				{Table: "b", Label: "b1", Values: map[string]any{}},
+				{Table: "b", Label: "b2", Values: map[string]any{"a_id": "a.a1"}},
+			},
+			want: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := New(tt.fixtures...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if got := set.Tables(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet_Truncate(t *testing.T) {
+	set, err := New(
+		&Fixture{Table: "a", Label: "a1", Values: map[string]any{}},
+		&Fixture{Table: "a", Label: "a2", Values: map[string]any{"b_id": "b.b1"}},
+		&Fixture{Table: "b", Label: "b1", Values: map[string]any{}},
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	db := sqltest.New(t)
+	db.ExpectExec(`^DELETE FROM a$`)
+	db.ExpectExec(`^DELETE FROM b$`)
+
+	if err := set.Truncate(context.Background(), db, sqldb.SQLite3); err != nil {
+		t.Errorf("Truncate() error = %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oleoneto/go-toolkit/cli/formatters"
+)
+
+type (
+	HealthReport struct {
+		Adapter   SQLAdapter    `json:"adapter" yaml:"adapter"`
+		Healthy   bool          `json:"healthy" yaml:"healthy"`
+		Error     string        `json:"error,omitempty" yaml:"error,omitempty"`
+		Latency   time.Duration `json:"latency" yaml:"latency"`
+		Version   string        `json:"version,omitempty" yaml:"version,omitempty"`
+		CheckedAt time.Time     `json:"checked_at" yaml:"checked_at"`
+
+		// Redacted data source name. Left for callers to fill in, since the database handle does not know it.
+		DSN string `json:"dsn,omitempty" yaml:"dsn,omitempty"`
+
+		// Connection pool statistics, for databases exposing them (i.e. *sql.DB).
+		Pool *PoolStats `json:"pool,omitempty" yaml:"pool,omitempty"`
+
+		SQLite *SQLiteHealth `json:"sqlite,omitempty" yaml:"sqlite,omitempty"`
+	}
+
+	// PoolStats - mirrors sql.DBStats.
+	PoolStats struct {
+		MaxOpenConnections int           `json:"max_open_connections" yaml:"max_open_connections"`
+		OpenConnections    int           `json:"open_connections" yaml:"open_connections"`
+		InUse              int           `json:"in_use" yaml:"in_use"`
+		Idle               int           `json:"idle" yaml:"idle"`
+		WaitCount          int64         `json:"wait_count" yaml:"wait_count"`
+		WaitDuration       time.Duration `json:"wait_duration" yaml:"wait_duration"`
+		MaxIdleClosed      int64         `json:"max_idle_closed" yaml:"max_idle_closed"`
+		MaxIdleTimeClosed  int64         `json:"max_idle_time_closed" yaml:"max_idle_time_closed"`
+		MaxLifetimeClosed  int64         `json:"max_lifetime_closed" yaml:"max_lifetime_closed"`
+	}
+
+	SQLiteHealth struct {
+		// Path of the main database file. Empty for in-memory databases.
+		File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+		// Size of the database file, or of its pages for in-memory databases.
+		Size int64 `json:"size" yaml:"size"`
+
+		JournalMode string `json:"journal_mode" yaml:"journal_mode"`
+
+		// Size of the write-ahead log not yet checkpointed into the database file. Zero outside of WAL mode.
+		WALSize int64 `json:"wal_size" yaml:"wal_size"`
+	}
+
+	statser interface {
+		Stats() sql.DBStats
+	}
+)
+
+// Health - pings the database and reports its latency, server version, pool statistics and,
+// for SQLite, file and journal details.
+//
+// The adapter is identified by the version query the database answers, so drivers wrapped
+// by WithQueryLog or a Cluster are reported like any other.
+//
+// A report is always returned; if the database cannot be reached, it is marked unhealthy
+// and the error is returned as well.
+//
+// Usage:
+//
+//	report, err := Health(ctx, db)
+//	fmt.Println(report)
+func Health(ctx context.Context, db SqlBackend) (*HealthReport, error) {
+	report := &HealthReport{CheckedAt: time.Now()}
+
+	if s, ok := db.(statser); ok {
+		report.Pool = newPoolStats(s.Stats())
+	}
+
+	start := time.Now()
+
+	var err error
+	if p, ok := db.(pinger); ok {
+		err = p.PingContext(ctx)
+	} else {
+		_, err = db.ExecContext(ctx, `SELECT 1`)
+	}
+
+	report.Latency = time.Since(start)
+
+	if err == nil {
+		err = report.inspect(ctx, db)
+	}
+
+	if err != nil {
+		report.Error = err.Error()
+		return report, fmt.Errorf("database unhealthy: %w", err)
+	}
+
+	report.Healthy = true
+
+	// Refreshed so the connection used by the checks is accounted for.
+	if s, ok := db.(statser); ok {
+		report.Pool = newPoolStats(s.Stats())
+	}
+
+	return report, nil
+}
+
+// inspect - identifies the adapter and fills in the server version and the details of the adapter.
+//
+// Postgres is asked first: SQLite rejects SHOW without a trace, whereas Postgres would log
+// the unknown sqlite_version function as an error.
+func (r *HealthReport) inspect(ctx context.Context, db SqlBackend) error {
+	err := db.QueryRowContext(ctx, `SHOW server_version`).Scan(&r.Version)
+	if err == nil {
+		r.Adapter = PostgreSQL
+		return nil
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	if err := db.QueryRowContext(ctx, `SELECT sqlite_version()`).Scan(&r.Version); err != nil {
+		return fmt.Errorf("unsupported database: %w", err)
+	}
+
+	r.Adapter = SQLite3
+	return r.inspectSQLite(ctx, db)
+}
+
+func (r *HealthReport) inspectSQLite(ctx context.Context, db SqlBackend) error {
+	health := &SQLiteHealth{}
+
+	// The columns of database_list are seq, name and file.
+	var (
+		seq  int
+		name string
+	)
+
+	if err := db.QueryRowContext(ctx, `PRAGMA database_list`).Scan(&seq, &name, &health.File); err != nil {
+		return err
+	}
+
+	if err := db.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&health.JournalMode); err != nil {
+		return err
+	}
+
+	health.JournalMode = strings.ToLower(health.JournalMode)
+
+	if health.File != "" {
+		info, err := os.Stat(health.File)
+		if err != nil {
+			return err
+		}
+
+		health.Size = info.Size()
+	} else {
+		var pages, pageSize int64
+		if err := db.QueryRowContext(ctx, `SELECT page_count, page_size FROM pragma_page_count(), pragma_page_size()`).Scan(&pages, &pageSize); err != nil {
+			return err
+		}
+
+		health.Size = pages * pageSize
+	}
+
+	if health.File != "" && health.JournalMode == "wal" {
+		// A missing log means it was checkpointed and removed when the last connection closed.
+		if info, err := os.Stat(health.File + "-wal"); err == nil {
+			health.WALSize = info.Size()
+		}
+	}
+
+	r.SQLite = health
+	return nil
+}
+
+func newPoolStats(stats sql.DBStats) *PoolStats {
+	return &PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// TableWriter - renders one row per property, so reports can be printed through formatters.TableFormatter.
+func (r HealthReport) TableWriter() table.Writer {
+	t := formatters.NewTableWriter(table.Row{"Property", "Value"})
+
+	for _, property := range r.properties() {
+		t.AppendRow(table.Row{property[0], property[1]})
+	}
+
+	return t
+}
+
+func (r HealthReport) String() string {
+	lines := []string{}
+
+	for _, property := range r.properties() {
+		lines = append(lines, fmt.Sprintf("%-23s %s", property[0]+":", property[1]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (r HealthReport) properties() [][2]string {
+	status := "healthy"
+	if !r.Healthy {
+		status = "unhealthy"
+	}
+
+	properties := [][2]string{{"Status", status}}
+
+	if r.Error != "" {
+		properties = append(properties, [2]string{"Error", r.Error})
+	}
+
+	if r.DSN != "" {
+		properties = append(properties, [2]string{"DSN", r.DSN})
+	}
+
+	if r.Adapter != "" {
+		properties = append(properties, [2]string{"Adapter", string(r.Adapter)})
+	}
+
+	if r.Version != "" {
+		properties = append(properties, [2]string{"Version", r.Version})
+	}
+
+	properties = append(properties, [2]string{"Latency", r.Latency.String()})
+
+	if p := r.Pool; p != nil {
+		maxOpen := "unlimited"
+		if p.MaxOpenConnections > 0 {
+			maxOpen = fmt.Sprint(p.MaxOpenConnections)
+		}
+
+		properties = append(properties,
+			[2]string{"Open connections", fmt.Sprintf("%d (max %s)", p.OpenConnections, maxOpen)},
+			[2]string{"In use / idle", fmt.Sprintf("%d / %d", p.InUse, p.Idle)},
+			[2]string{"Waits", fmt.Sprintf("%d (%s)", p.WaitCount, p.WaitDuration)},
+			[2]string{"Closed (idle/lifetime)", fmt.Sprintf("%d / %d", p.MaxIdleClosed+p.MaxIdleTimeClosed, p.MaxLifetimeClosed)},
+		)
+	}
+
+	if s := r.SQLite; s != nil {
+		file := s.File
+		if file == "" {
+			file = "(memory)"
+		}
+
+		properties = append(properties,
+			[2]string{"File", file},
+			[2]string{"Size", fmt.Sprintf("%d bytes", s.Size)},
+			[2]string{"Journal mode", s.JournalMode},
+		)
+
+		if s.JournalMode == "wal" {
+			properties = append(properties, [2]string{"WAL size", fmt.Sprintf("%d bytes", s.WALSize)})
+		}
+	}
+
+	return properties
+}
@@ -0,0 +1,132 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+// errNoShow - what SQLite answers to the Postgres version query.
+var errNoShow = errors.New(`near "SHOW": syntax error`)
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(db *sqltest.Backend)
+		want   HealthReport
+	}{
+		{
+			name: "sqlite3 in memory",
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SHOW server_version$`).WillReturnError(errNoShow)
+				db.ExpectQuery(`^SELECT sqlite_version\(\)$`).WillReturnRows([]string{"version"}, []any{"3.45.1"})
+				db.ExpectQuery(`^PRAGMA database_list$`).WillReturnRows([]string{"seq", "name", "file"}, []any{int64(0), "main", ""})
+				db.ExpectQuery(`^PRAGMA journal_mode$`).WillReturnRows([]string{"journal_mode"}, []any{"MEMORY"})
+				db.ExpectQuery(`FROM pragma_page_count\(\), pragma_page_size\(\)$`).WillReturnRows([]string{"page_count", "page_size"}, []any{int64(3), int64(4096)})
+			},
+			want: HealthReport{
+				Adapter: SQLite3,
+				Healthy: true,
+				Version: "3.45.1",
+				SQLite:  &SQLiteHealth{Size: 12288, JournalMode: "memory"},
+			},
+		},
+		{
+			name: "postgresql",
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SHOW server_version$`).WillReturnRows([]string{"server_version"}, []any{"16.4"})
+			},
+			want: HealthReport{Adapter: PostgreSQL, Healthy: true, Version: "16.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			tt.expect(db)
+
+			report, err := Health(context.Background(), db)
+			if err != nil {
+				t.Fatalf("Health() error = %v", err)
+			}
+
+			if report.Latency <= 0 || report.Pool == nil || report.CheckedAt.IsZero() {
+				t.Errorf("Health() = %+v, want latency, pool stats and check time", report)
+			}
+
+			got := *report
+			got.Latency, got.Pool, got.CheckedAt = 0, nil, tt.want.CheckedAt
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Health() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealth_wal(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.db")
+
+	if err := os.WriteFile(file, make([]byte, 8192), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file+"-wal", make([]byte, 1024), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db := sqltest.New(t)
+	db.ExpectQuery(`^SHOW server_version$`).WillReturnError(errNoShow)
+	db.ExpectQuery(`^SELECT sqlite_version\(\)$`).WillReturnRows([]string{"version"}, []any{"3.45.1"})
+	db.ExpectQuery(`^PRAGMA database_list$`).WillReturnRows([]string{"seq", "name", "file"}, []any{int64(0), "main", file})
+	db.ExpectQuery(`^PRAGMA journal_mode$`).WillReturnRows([]string{"journal_mode"}, []any{"wal"})
+
+	report, err := Health(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+
+	want := &SQLiteHealth{File: file, Size: 8192, JournalMode: "wal", WALSize: 1024}
+	if !reflect.DeepEqual(report.SQLite, want) {
+		t.Errorf("Health() = %+v, want %+v", report.SQLite, want)
+	}
+}
+
+func TestHealth_unhealthy(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(db *sqltest.Backend)
+	}{
+		{name: "unreachable", expect: func(db *sqltest.Backend) { db.Close() }},
+		{
+			name: "timeout",
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SHOW server_version$`).WillReturnError(context.DeadlineExceeded)
+			},
+		},
+		{
+			name: "unsupported database",
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SHOW server_version$`).WillReturnError(errNoShow)
+				db.ExpectQuery(`^SELECT sqlite_version\(\)$`).WillReturnError(errors.New("FUNCTION sqlite_version does not exist"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			tt.expect(db)
+
+			report, err := Health(context.Background(), db)
+			if err == nil || report.Healthy || report.Error == "" {
+				t.Errorf("Health() = %+v, %v, want an unhealthy report and an error", report, err)
+			}
+		})
+	}
+}
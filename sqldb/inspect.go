@@ -0,0 +1,486 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oleoneto/go-toolkit/cli/formatters"
+)
+
+type ConstraintType string
+
+const (
+	CONSTRAINT_PRIMARY_KEY ConstraintType = "PRIMARY KEY"
+	CONSTRAINT_UNIQUE      ConstraintType = "UNIQUE"
+	CONSTRAINT_CHECK       ConstraintType = "CHECK"
+)
+
+type (
+	Schema struct {
+		Tables []Table `json:"tables" yaml:"tables"`
+	}
+
+	Table struct {
+		Name        string       `json:"name" yaml:"name"`
+		Columns     []Column     `json:"columns" yaml:"columns"`
+		Indexes     []Index      `json:"indexes" yaml:"indexes"`
+		ForeignKeys []ForeignKey `json:"foreign_keys" yaml:"foreign_keys"`
+		Constraints []Constraint `json:"constraints" yaml:"constraints"`
+	}
+
+	Column struct {
+		Name     string  `json:"name" yaml:"name"`
+		Type     string  `json:"type" yaml:"type"`
+		Nullable bool    `json:"nullable" yaml:"nullable"`
+		Default  *string `json:"default" yaml:"default"`
+	}
+
+	// Index - a secondary index. Indexes backing primary keys are described by a Constraint instead.
+	Index struct {
+		Name    string   `json:"name" yaml:"name"`
+		Columns []string `json:"columns" yaml:"columns"`
+		Unique  bool     `json:"unique" yaml:"unique"`
+	}
+
+	ForeignKey struct {
+		Name              string   `json:"name" yaml:"name"`
+		Columns           []string `json:"columns" yaml:"columns"`
+		ReferencedTable   string   `json:"referenced_table" yaml:"referenced_table"`
+		ReferencedColumns []string `json:"referenced_columns" yaml:"referenced_columns"`
+		OnUpdate          string   `json:"on_update" yaml:"on_update"`
+		OnDelete          string   `json:"on_delete" yaml:"on_delete"`
+	}
+
+	Constraint struct {
+		Name       string         `json:"name" yaml:"name"`
+		Type       ConstraintType `json:"type" yaml:"type"`
+		Columns    []string       `json:"columns" yaml:"columns"`
+		Definition string         `json:"definition,omitempty" yaml:"definition,omitempty"`
+	}
+)
+
+// Postgres encodes referential actions as single characters in pg_constraint.
+var pgReferentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// Inspect - describes the tables of the database, sorted by name.
+//
+// On Postgres, only the tables of the current schema (see `search_path`) are described.
+// On SQLite, CHECK constraints are not reported, as they are only available as part of the table's SQL.
+//
+// Usage:
+//
+//	schema, err := Inspect(ctx, db, PostgreSQL)
+//	state.Writer.Print(schema)
+func Inspect(ctx context.Context, db SqlBackend, adapter SQLAdapter) (*Schema, error) {
+	switch adapter {
+	case PostgreSQL:
+		return inspectPostgres(ctx, db)
+	case SQLite3:
+		return inspectSQLite(ctx, db)
+	}
+
+	return nil, fmt.Errorf("unsupported adapter: %q", adapter)
+}
+
+// Table - returns the table with the given name.
+func (s Schema) Table(name string) (Table, bool) {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return Table{}, false
+}
+
+// TableWriter - renders one row per column, so schemas can be printed through formatters.TableFormatter.
+func (s Schema) TableWriter() table.Writer {
+	t := formatters.NewTableWriter(table.Row{"Table", "Column", "Type", "Nullable", "Default"})
+
+	for _, tbl := range s.Tables {
+		for _, column := range tbl.Columns {
+			t.AppendRow(table.Row{tbl.Name, column.Name, column.Type, column.Nullable, column.defaultValue()})
+		}
+	}
+
+	return t
+}
+
+func (s Schema) String() string {
+	lines := []string{}
+
+	for _, tbl := range s.Tables {
+		lines = append(lines, tbl.Name)
+
+		for _, column := range tbl.Columns {
+			line := fmt.Sprintf("  %s %s", column.Name, column.Type)
+			if !column.Nullable {
+				line += " NOT NULL"
+			}
+
+			if column.Default != nil {
+				line += " DEFAULT " + *column.Default
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func newTable(name string) Table {
+	return Table{Name: name, Columns: []Column{}, Indexes: []Index{}, ForeignKeys: []ForeignKey{}, Constraints: []Constraint{}}
+}
+
+func (c Column) defaultValue() string {
+	if c.Default == nil {
+		return ""
+	}
+
+	return *c.Default
+}
+
+func inspectPostgres(ctx context.Context, db SqlBackend) (*Schema, error) {
+	tables := map[string]*Table{}
+	schema := &Schema{Tables: []Table{}}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p')
+		ORDER BY c.relname`)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := ScanRows[string](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		schema.Tables = append(schema.Tables, newTable(name))
+	}
+
+	for i := range schema.Tables {
+		tables[schema.Tables[i].Name] = &schema.Tables[i]
+	}
+
+	err = each(ctx, db, func(rows *sql.Rows) error {
+		var tableName string
+		var column Column
+
+		if err := rows.Scan(&tableName, &column.Name, &column.Type, &column.Nullable, &column.Default); err != nil {
+			return err
+		}
+
+		if t, ok := tables[tableName]; ok {
+			t.Columns = append(t.Columns, column)
+		}
+
+		return nil
+	}, `
+		SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_get_expr(d.adbin, d.adrelid)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY c.relname, a.attnum`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = each(ctx, db, func(rows *sql.Rows) error {
+		var tableName string
+		var columns []byte
+		var index Index
+
+		if err := rows.Scan(&tableName, &index.Name, &index.Unique, &columns); err != nil {
+			return err
+		}
+
+		if err := unmarshalNames(columns, &index.Columns); err != nil {
+			return err
+		}
+
+		if t, ok := tables[tableName]; ok {
+			t.Indexes = append(t.Indexes, index)
+		}
+
+		return nil
+	}, `
+		SELECT t.relname, i.relname, ix.indisunique,
+			(SELECT json_agg(pg_get_indexdef(ix.indexrelid, k + 1, true) ORDER BY k) FROM generate_subscripts(ix.indkey, 1) AS k)
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND NOT ix.indisprimary
+		ORDER BY t.relname, i.relname`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = each(ctx, db, func(rows *sql.Rows) error {
+		var tableName, onUpdate, onDelete string
+		var columns, referencedColumns []byte
+		var fk ForeignKey
+
+		if err := rows.Scan(&tableName, &fk.Name, &columns, &fk.ReferencedTable, &referencedColumns, &onUpdate, &onDelete); err != nil {
+			return err
+		}
+
+		if err := unmarshalNames(columns, &fk.Columns); err != nil {
+			return err
+		}
+
+		if err := unmarshalNames(referencedColumns, &fk.ReferencedColumns); err != nil {
+			return err
+		}
+
+		fk.OnUpdate, fk.OnDelete = pgReferentialActions[onUpdate], pgReferentialActions[onDelete]
+
+		if t, ok := tables[tableName]; ok {
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+
+		return nil
+	}, `
+		SELECT t.relname, c.conname,
+			(SELECT json_agg(a.attname ORDER BY k.n) FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum),
+			r.relname,
+			(SELECT json_agg(a.attname ORDER BY k.n) FROM unnest(c.confkey) WITH ORDINALITY AS k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = c.confrelid AND a.attnum = k.attnum),
+			c.confupdtype::text, c.confdeltype::text
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_class r ON r.oid = c.confrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND c.contype = 'f'
+		ORDER BY t.relname, c.conname`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = each(ctx, db, func(rows *sql.Rows) error {
+		var tableName string
+		var columns []byte
+		var constraint Constraint
+
+		if err := rows.Scan(&tableName, &constraint.Name, &constraint.Type, &columns, &constraint.Definition); err != nil {
+			return err
+		}
+
+		if err := unmarshalNames(columns, &constraint.Columns); err != nil {
+			return err
+		}
+
+		if t, ok := tables[tableName]; ok {
+			t.Constraints = append(t.Constraints, constraint)
+		}
+
+		return nil
+	}, `
+		SELECT t.relname, c.conname,
+			CASE c.contype WHEN 'p' THEN 'PRIMARY KEY' WHEN 'u' THEN 'UNIQUE' ELSE 'CHECK' END,
+			(SELECT json_agg(a.attname ORDER BY k.n) FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum),
+			pg_get_constraintdef(c.oid)
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND c.contype IN ('p', 'u', 'c')
+		ORDER BY t.relname, c.conname`)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+func inspectSQLite(ctx context.Context, db SqlBackend) (*Schema, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := ScanRows[string](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{Tables: make([]Table, len(names))}
+
+	for i, name := range names {
+		t := newTable(name)
+		primaryKey := map[int]string{}
+
+		err := each(ctx, db, func(rows *sql.Rows) error {
+			var column Column
+			var notNull, pk int
+
+			if err := rows.Scan(&column.Name, &column.Type, &notNull, &column.Default, &pk); err != nil {
+				return err
+			}
+
+			column.Nullable = notNull == 0
+			if pk > 0 {
+				primaryKey[pk] = column.Name
+			}
+
+			t.Columns = append(t.Columns, column)
+			return nil
+		}, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(primaryKey) > 0 {
+			constraint := Constraint{Type: CONSTRAINT_PRIMARY_KEY, Columns: []string{}}
+			for position := 1; position <= len(primaryKey); position++ {
+				constraint.Columns = append(constraint.Columns, primaryKey[position])
+			}
+
+			t.Constraints = append(t.Constraints, constraint)
+		}
+
+		if err := inspectSQLiteIndexes(ctx, db, &t); err != nil {
+			return nil, err
+		}
+
+		if err := inspectSQLiteForeignKeys(ctx, db, &t); err != nil {
+			return nil, err
+		}
+
+		schema.Tables[i] = t
+	}
+
+	return schema, nil
+}
+
+func inspectSQLiteIndexes(ctx context.Context, db SqlBackend, t *Table) error {
+	type indexEntry struct {
+		Name   string
+		Unique bool
+		Origin string
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT name, "unique", origin FROM pragma_index_list(?) ORDER BY name`, t.Name)
+	if err != nil {
+		return err
+	}
+
+	entries, err := ScanRows[indexEntry](rows)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		// The primary key is already described by its constraint.
+		if entry.Origin == "pk" {
+			continue
+		}
+
+		rows, err := db.QueryContext(ctx, `SELECT coalesce(name, '<expression>') FROM pragma_index_info(?) ORDER BY seqno`, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		columns, err := ScanRows[string](rows)
+		if err != nil {
+			return err
+		}
+
+		t.Indexes = append(t.Indexes, Index{Name: entry.Name, Columns: columns, Unique: entry.Unique})
+
+		if entry.Origin == "u" {
+			t.Constraints = append(t.Constraints, Constraint{Name: entry.Name, Type: CONSTRAINT_UNIQUE, Columns: columns})
+		}
+	}
+
+	return nil
+}
+
+func inspectSQLiteForeignKeys(ctx context.Context, db SqlBackend, t *Table) error {
+	keys := map[int]*ForeignKey{}
+	ids := []int{}
+
+	err := each(ctx, db, func(rows *sql.Rows) error {
+		var id int
+		var from, referencedTable, onUpdate, onDelete string
+		var to sql.NullString
+
+		if err := rows.Scan(&id, &referencedTable, &from, &to, &onUpdate, &onDelete); err != nil {
+			return err
+		}
+
+		fk, ok := keys[id]
+		if !ok {
+			fk = &ForeignKey{Columns: []string{}, ReferencedTable: referencedTable, ReferencedColumns: []string{}, OnUpdate: onUpdate, OnDelete: onDelete}
+			keys[id] = fk
+			ids = append(ids, id)
+		}
+
+		fk.Columns = append(fk.Columns, from)
+
+		// A NULL target refers to the primary key of the referenced table.
+		if to.Valid {
+			fk.ReferencedColumns = append(fk.ReferencedColumns, to.String)
+		}
+
+		return nil
+	}, `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, t.Name)
+	if err != nil {
+		return err
+	}
+
+	sort.Ints(ids)
+	for _, id := range ids {
+		t.ForeignKeys = append(t.ForeignKeys, *keys[id])
+	}
+
+	return nil
+}
+
+// each - runs the query and calls fn for every row.
+func each(ctx context.Context, db SqlBackend, fn func(rows *sql.Rows) error, query string, args ...any) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// unmarshalNames - decodes a JSON array of names, as aggregated by json_agg. NULL decodes to an empty list.
+func unmarshalNames(data []byte, names *[]string) error {
+	*names = []string{}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, names)
+}
@@ -0,0 +1,73 @@
+package sqldb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func TestInspect_sqlite(t *testing.T) {
+	db := sqltest.New(t)
+
+	db.ExpectQuery(`FROM sqlite_master`).WillReturnRows([]string{"name"}, []any{"posts"})
+
+	db.ExpectQuery(`FROM pragma_table_info`).WithArgs("posts").WillReturnRows(
+		[]string{"name", "type", "notnull", "dflt_value", "pk"},
+		[]any{"id", "INTEGER", int64(0), nil, int64(2)},
+		[]any{"slug", "TEXT", int64(1), "'draft'", int64(1)},
+		[]any{"author_id", "INTEGER", int64(0), nil, int64(0)},
+	)
+
+	db.ExpectQuery(`FROM pragma_index_list`).WithArgs("posts").WillReturnRows(
+		[]string{"name", "unique", "origin"},
+		[]any{"posts_author", int64(0), "c"},
+		[]any{"sqlite_autoindex_posts_1", int64(1), "pk"},
+		[]any{"sqlite_autoindex_posts_2", int64(1), "u"},
+	)
+
+	db.ExpectQuery(`FROM pragma_index_info`).WithArgs("posts_author").WillReturnRows([]string{"name"}, []any{"author_id"})
+	db.ExpectQuery(`FROM pragma_index_info`).WithArgs("sqlite_autoindex_posts_2").WillReturnRows([]string{"name"}, []any{"slug"})
+
+	db.ExpectQuery(`FROM pragma_foreign_key_list`).WithArgs("posts").WillReturnRows(
+		[]string{"id", "table", "from", "to", "on_update", "on_delete"},
+		[]any{int64(0), "users", "author_id", nil, "NO ACTION", "CASCADE"},
+	)
+
+	schema, err := Inspect(context.Background(), db, SQLite3)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+
+	want := &Schema{Tables: []Table{{
+		Name: "posts",
+		Columns: []Column{
+			{Name: "id", Type: "INTEGER", Nullable: true},
+			{Name: "slug", Type: "TEXT", Default: helpers.PointerTo("'draft'")},
+			{Name: "author_id", Type: "INTEGER", Nullable: true},
+		},
+		Indexes: []Index{
+			{Name: "posts_author", Columns: []string{"author_id"}},
+			{Name: "sqlite_autoindex_posts_2", Columns: []string{"slug"}, Unique: true},
+		},
+		ForeignKeys: []ForeignKey{
+			{Columns: []string{"author_id"}, ReferencedTable: "users", ReferencedColumns: []string{}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+		},
+		Constraints: []Constraint{
+			{Type: CONSTRAINT_PRIMARY_KEY, Columns: []string{"slug", "id"}},
+			{Name: "sqlite_autoindex_posts_2", Type: CONSTRAINT_UNIQUE, Columns: []string{"slug"}},
+		},
+	}}}
+
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("Inspect() = %+v, want %+v", schema, want)
+	}
+}
+
+func TestInspect_unsupportedAdapter(t *testing.T) {
+	if _, err := Inspect(context.Background(), sqltest.New(t), SQLAdapter("mysql")); err == nil {
+		t.Errorf("Inspect() error = nil, want unsupported adapter")
+	}
+}
@@ -0,0 +1,395 @@
+// Package jobs implements a durable work queue stored in a database table.
+//
+// Jobs are claimed for a visibility timeout: if the worker that claimed a job neither completes
+// nor fails it in time (i.e. because it crashed), the job becomes visible to other workers again.
+// Failed jobs are retried with backoff until they run out of attempts, and are then dead-lettered:
+// kept in the table with their last error until they are retried manually.
+//
+// On Postgres, concurrent workers claim jobs with `SELECT ... FOR UPDATE SKIP LOCKED`.
+// On SQLite, claims are single UPDATE ... RETURNING statements, which SQLite serializes.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+const (
+	DEFAULT_TABLE_NAME         = "jobs"
+	DEFAULT_QUEUE              = "default"
+	DEFAULT_MAX_ATTEMPTS       = 5
+	DEFAULT_VISIBILITY_TIMEOUT = 5 * time.Minute
+)
+
+type Status string
+
+const (
+	PENDING Status = "pending"
+	RUNNING Status = "running"
+	DEAD    Status = "dead"
+)
+
+var (
+	ErrNoJob = errors.New("no job available")
+
+	// Returned when completing or failing a job whose claim expired and may have been taken by another worker.
+	ErrClaimLost = errors.New("job claim lost")
+
+	jobColumns = "id, queue, payload, status, attempts, max_attempts, run_at, last_error, created_at"
+)
+
+type (
+	Queue struct {
+		db      sqldb.SqlBackend
+		adapter sqldb.SQLAdapter
+		table   string
+		options Options
+
+		now func() time.Time
+	}
+
+	Options struct {
+		// The database engine storing the queue. Must be one of sqldb.PostgreSQL or sqldb.SQLite3.
+		Adapter sqldb.SQLAdapter
+
+		// Name of the table storing jobs. Defaults to `jobs`.
+		TableName string
+
+		// Name of the queue jobs are enqueued to and claimed from. Defaults to `default`.
+		// Several queues can share the same table.
+		Name string
+
+		// Number of times a job is attempted before it is dead-lettered. Defaults to DEFAULT_MAX_ATTEMPTS.
+		MaxAttempts int
+
+		// How long a claimed job stays hidden from other workers. Defaults to DEFAULT_VISIBILITY_TIMEOUT.
+		VisibilityTimeout time.Duration
+
+		// Returns how long to wait before retrying a job that failed its nth attempt.
+		// Defaults to sqldb.ExponentialBackoff.
+		Backoff func(attempt int) time.Duration
+	}
+
+	Job struct {
+		ID          int64           `json:"id" yaml:"id"`
+		Queue       string          `json:"queue" yaml:"queue"`
+		Payload     json.RawMessage `json:"payload" yaml:"payload"`
+		Status      Status          `json:"status" yaml:"status"`
+		Attempts    int             `json:"attempts" yaml:"attempts"`
+		MaxAttempts int             `json:"max_attempts" yaml:"max_attempts"`
+		RunAt       time.Time       `json:"run_at" yaml:"run_at"`
+		LastError   string          `json:"last_error,omitempty" yaml:"last_error,omitempty"`
+		CreatedAt   time.Time       `json:"created_at" yaml:"created_at"`
+	}
+
+	// Implemented by both sqldb.SqlBackend and *sql.Tx.
+	executor interface {
+		QueryRowContext(context.Context, string, ...any) *sql.Row
+	}
+)
+
+// New - returns the queue named in `options`, stored in the given database.
+//
+// Usage:
+//
+//	queue, err := jobs.New(db, jobs.Options{Adapter: sqldb.PostgreSQL, Name: "emails"})
+//	err = queue.Init(ctx)
+//	id, err := queue.Enqueue(ctx, Email{To: "john@example.com"})
+func New(db sqldb.SqlBackend, options Options) (*Queue, error) {
+	if db == nil {
+		return nil, fmt.Errorf("no database provided")
+	}
+
+	if options.Adapter != sqldb.PostgreSQL && options.Adapter != sqldb.SQLite3 {
+		return nil, fmt.Errorf("unsupported adapter: %q", options.Adapter)
+	}
+
+	table := options.TableName
+	if table == "" {
+		table = DEFAULT_TABLE_NAME
+	}
+
+	if !sqldb.IdentifierValidationPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid jobs table name: %q", table)
+	}
+
+	if options.Name == "" {
+		options.Name = DEFAULT_QUEUE
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DEFAULT_MAX_ATTEMPTS
+	}
+
+	if options.VisibilityTimeout <= 0 {
+		options.VisibilityTimeout = DEFAULT_VISIBILITY_TIMEOUT
+	}
+
+	if options.Backoff == nil {
+		options.Backoff = sqldb.ExponentialBackoff
+	}
+
+	return &Queue{db: db, adapter: options.Adapter, table: table, options: options, now: time.Now}, nil
+}
+
+// Init - creates the jobs table if it does not exist yet.
+//
+// Timestamps are stored as Unix milliseconds so they compare the same way on every adapter.
+func (q *Queue) Init(ctx context.Context) error {
+	id, payload := "INTEGER PRIMARY KEY AUTOINCREMENT", "BLOB"
+	index := fmt.Sprintf("%s_claim_idx ON %s", q.table, q.table)
+
+	if schema, table, ok := strings.Cut(q.table, "."); ok {
+		// SQLite qualifies the index with the schema, Postgres creates it in the schema of the table.
+		index = fmt.Sprintf("%s.%s_claim_idx ON %s", schema, table, table)
+	}
+
+	if q.adapter == sqldb.PostgreSQL {
+		id, payload = "BIGSERIAL PRIMARY KEY", "BYTEA"
+		index = fmt.Sprintf("%s_claim_idx ON %s", strings.ReplaceAll(q.table, ".", "_"), q.table)
+	}
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id %s,
+			queue TEXT NOT NULL,
+			payload %s NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			max_attempts INTEGER NOT NULL,
+			run_at BIGINT NOT NULL,
+			locked_until BIGINT,
+			last_error TEXT,
+			created_at BIGINT NOT NULL,
+			updated_at BIGINT NOT NULL
+		)`, q.table, id, payload),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s (queue, status, run_at)`, index),
+	}
+
+	for _, statement := range statements {
+		if _, err := q.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Enqueue - adds a job with the JSON encoding of the payload, ready to run immediately.
+//
+// If the context carries a transaction started by sqldb.WithTx, the job is enqueued as part of it,
+// so it only becomes visible to workers if the transaction commits.
+func (q *Queue) Enqueue(ctx context.Context, payload any) (int64, error) {
+	return q.Schedule(ctx, payload, q.now())
+}
+
+// Schedule - adds a job with the JSON encoding of the payload, hidden from workers until `runAt`.
+func (q *Queue) Schedule(ctx context.Context, payload any, runAt time.Time) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	var db executor = q.db
+	if tx, ok := sqldb.TxFromContext(ctx); ok {
+		db = tx
+	}
+
+	now := q.now()
+	placeholders := helpers.EnumerateArgs(7, func(index, _ int) string { return q.adapter.Placeholder(index) })
+
+	var id int64
+	err = db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %s (queue, payload, status, max_attempts, run_at, created_at, updated_at) VALUES (%s) RETURNING id`, q.table, placeholders),
+		q.options.Name, data, string(PENDING), q.options.MaxAttempts, runAt.UnixMilli(), now.UnixMilli(), now.UnixMilli(),
+	).Scan(&id)
+
+	return id, err
+}
+
+// Dequeue - claims the next job that is due, or returns ErrNoJob if there is none.
+//
+// The claimed job must be passed to Complete or Fail before the visibility timeout expires,
+// after which other workers may claim it again. Jobs whose claim expired on their last attempt
+// are dead-lettered instead of being returned.
+func (q *Queue) Dequeue(ctx context.Context) (*Job, error) {
+	lock := ""
+	if q.adapter == sqldb.PostgreSQL {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+
+	p := q.adapter.Placeholder
+
+	statement := fmt.Sprintf(
+		`UPDATE %[1]s SET status = '%[2]s', attempts = attempts + 1, locked_until = %[4]s, updated_at = %[5]s
+		WHERE id = (
+			SELECT id FROM %[1]s
+			WHERE queue = %[6]s AND ((status = '%[3]s' AND run_at <= %[7]s) OR (status = '%[2]s' AND locked_until <= %[8]s))
+			ORDER BY run_at, id
+			LIMIT 1%[9]s
+		)
+		RETURNING %[10]s`,
+		q.table, RUNNING, PENDING, p(1), p(2), p(3), p(4), p(5), lock, jobColumns,
+	)
+
+	for {
+		now := q.now()
+		lockedUntil := now.Add(q.options.VisibilityTimeout).UnixMilli()
+
+		job, err := scanJob(q.db.QueryRowContext(ctx, statement, lockedUntil, now.UnixMilli(), q.options.Name, now.UnixMilli(), now.UnixMilli()))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoJob
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if job.Attempts <= job.MaxAttempts {
+			return job, nil
+		}
+
+		// The worker running the last attempt did not report back in time.
+		if err := q.update(ctx, job, DEAD, "visibility timeout expired", job.RunAt); err != nil && !errors.Is(err, ErrClaimLost) {
+			return nil, err
+		}
+	}
+}
+
+// Complete - removes a job that was processed successfully.
+func (q *Queue) Complete(ctx context.Context, job *Job) error {
+	result, err := q.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = %s AND attempts = %s AND status = '%s'`, q.table, q.adapter.Placeholder(1), q.adapter.Placeholder(2), RUNNING),
+		job.ID, job.Attempts,
+	)
+	if err != nil {
+		return err
+	}
+
+	return claimed(result)
+}
+
+// Fail - records the error of a claimed job, then schedules it to be retried after the backoff delay,
+// or dead-letters it if this was its last attempt.
+func (q *Queue) Fail(ctx context.Context, job *Job, cause error) error {
+	message := ""
+	if cause != nil {
+		message = cause.Error()
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		return q.update(ctx, job, DEAD, message, job.RunAt)
+	}
+
+	return q.update(ctx, job, PENDING, message, q.now().Add(q.options.Backoff(job.Attempts)))
+}
+
+// DeadLetters - returns the jobs of the queue that ran out of attempts, oldest first.
+func (q *Queue) DeadLetters(ctx context.Context) ([]Job, error) {
+	rows, err := q.db.QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT %s FROM %s WHERE queue = %s AND status = '%s' ORDER BY id`, jobColumns, q.table, q.adapter.Placeholder(1), DEAD),
+		q.options.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Retry - makes a dead-lettered job pending again, with a fresh set of attempts.
+func (q *Queue) Retry(ctx context.Context, id int64) error {
+	now := q.now().UnixMilli()
+	p := q.adapter.Placeholder
+
+	result, err := q.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`UPDATE %s SET status = '%s', attempts = 0, run_at = %s, updated_at = %s WHERE id = %s AND status = '%s'`, q.table, PENDING, p(1), p(2), p(3), DEAD),
+		now, now, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = fmt.Errorf("no dead job with id %d in queue %q", id, q.options.Name)
+	}
+
+	return err
+}
+
+// Decode - unmarshals the JSON payload of the job into `v`.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// update - releases the claim on a job, moving it to the given status.
+func (q *Queue) update(ctx context.Context, job *Job, status Status, message string, runAt time.Time) error {
+	p := q.adapter.Placeholder
+
+	result, err := q.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE %s SET status = %s, last_error = %s, run_at = %s, locked_until = NULL, updated_at = %s WHERE id = %s AND attempts = %s AND status = '%s'`,
+			q.table, p(1), p(2), p(3), p(4), p(5), p(6), RUNNING,
+		),
+		string(status), message, runAt.UnixMilli(), q.now().UnixMilli(), job.ID, job.Attempts,
+	)
+	if err != nil {
+		return err
+	}
+
+	return claimed(result)
+}
+
+// claimed - returns ErrClaimLost if the statement releasing a claim did not find it.
+func claimed(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrClaimLost
+	}
+
+	return err
+}
+
+func scanJob(row interface{ Scan(...any) error }) (*Job, error) {
+	var (
+		job              Job
+		runAt, createdAt int64
+		lastError        sql.NullString
+	)
+
+	err := row.Scan(&job.ID, &job.Queue, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts, &runAt, &lastError, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	job.RunAt = time.UnixMilli(runAt)
+	job.CreatedAt = time.UnixMilli(createdAt)
+	job.LastError = lastError.String
+
+	return &job, nil
+}
@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+var now = time.UnixMilli(1700000000000)
+
+func newQueue(t *testing.T, db sqldb.SqlBackend) *Queue {
+	q, err := New(db, Options{Adapter: sqldb.SQLite3, MaxAttempts: 3, VisibilityTimeout: time.Minute})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	q.now = func() time.Time { return now }
+	return q
+}
+
+func jobRow(id int64, attempts int) []any {
+	return []any{id, "default", []byte(`{"to":"john"}`), "running", int64(attempts), int64(3), now.UnixMilli(), nil, now.UnixMilli()}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{name: "defaults", options: Options{Adapter: sqldb.PostgreSQL}},
+		{name: "schema qualified table", options: Options{Adapter: sqldb.SQLite3, TableName: "queue.jobs"}},
+		{name: "unsupported adapter", options: Options{Adapter: "mysql"}, wantErr: true},
+		{name: "invalid table", options: Options{Adapter: sqldb.SQLite3, TableName: "jobs; DROP TABLE users"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(sqltest.NewBackend(), tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueue_Dequeue(t *testing.T) {
+	db := sqltest.New(t)
+	q := newQueue(t, db)
+
+	lockedUntil := now.Add(time.Minute).UnixMilli()
+	columns := []string{"id", "queue", "payload", "status", "attempts", "max_attempts", "run_at", "last_error", "created_at"}
+
+	// The first job's last attempt expired, so it is dead-lettered and the next one is claimed.
+	db.ExpectQuery(`^UPDATE jobs SET status = 'running'`).
+		WithArgs(lockedUntil, now.UnixMilli(), "default", now.UnixMilli(), now.UnixMilli()).
+		WillReturnRows(columns, jobRow(1, 4))
+	db.ExpectExec(`^UPDATE jobs SET status = \?, last_error = \?`).
+		WithArgs(string(DEAD), "visibility timeout expired", now.UnixMilli(), now.UnixMilli(), int64(1), 4).
+		WillReturnResult(0, 1)
+	db.ExpectQuery(`^UPDATE jobs SET status = 'running'`).WillReturnRows(columns, jobRow(2, 1))
+	db.ExpectQuery(`^UPDATE jobs SET status = 'running'`).WillReturnRows(columns)
+
+	job, err := q.Dequeue(context.Background())
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+
+	var payload struct{ To string }
+	if err := job.Decode(&payload); err != nil || job.ID != 2 || payload.To != "john" {
+		t.Errorf("Dequeue() = %+v, %+v, want job 2 for john", job, payload)
+	}
+
+	if _, err := q.Dequeue(context.Background()); !errors.Is(err, ErrNoJob) {
+		t.Errorf("Dequeue() error = %v, want %v", err, ErrNoJob)
+	}
+}
+
+func TestQueue_Fail(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		status   Status
+		runAt    time.Time
+		affected int64
+		wantErr  error
+	}{
+		{name: "retries with backoff", attempts: 2, status: PENDING, runAt: now.Add(2 * time.Second), affected: 1},
+		{name: "dead-letters last attempt", attempts: 3, status: DEAD, runAt: now, affected: 1},
+		{name: "claim lost", attempts: 1, status: PENDING, runAt: now.Add(time.Second), wantErr: ErrClaimLost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			q := newQueue(t, db)
+
+			db.ExpectExec(`^UPDATE jobs SET status = \?`).
+				WithArgs(string(tt.status), "boom", tt.runAt.UnixMilli(), now.UnixMilli(), int64(7), tt.attempts).
+				WillReturnResult(0, tt.affected)
+
+			job := &Job{ID: 7, Attempts: tt.attempts, MaxAttempts: 3, RunAt: now}
+
+			if err := q.Fail(context.Background(), job, errors.New("boom")); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Fail() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueue_Work_shutdown(t *testing.T) {
+	db := sqltest.New(t)
+	q := newQueue(t, db)
+
+	columns := []string{"id", "queue", "payload", "status", "attempts", "max_attempts", "run_at", "last_error", "created_at"}
+
+	db.ExpectQuery(`^UPDATE jobs SET status = 'running'`).WillReturnRows(columns, jobRow(1, 1))
+	db.ExpectQuery(`^UPDATE jobs SET status = 'running'`).WillReturnRows(columns, jobRow(2, 1))
+	db.ExpectExec(`^DELETE FROM jobs WHERE id = \? AND attempts = \?`).WithArgs(sqltest.AnyArg, 1).WillReturnResult(0, 1).Times(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	errs := make(chan error, 2)
+
+	done := make(chan error)
+	go func() {
+		done <- q.Work(ctx, func(handlerCtx context.Context, job *Job) error {
+			started <- struct{}{}
+			<-ctx.Done()
+
+			// In-flight jobs keep running after the worker context is cancelled.
+			time.Sleep(10 * time.Millisecond)
+			errs <- handlerCtx.Err()
+
+			return nil
+		}, WorkerOptions{Concurrency: 2, OnError: func(job *Job, err error) { t.Errorf("OnError(%v, %v)", job, err) }})
+	}()
+
+	<-started
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Work() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Work() did not return after the context was cancelled")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("handler context error = %v, want nil", err)
+		}
+	}
+}
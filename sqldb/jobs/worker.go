@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const DEFAULT_POLL_INTERVAL = time.Second
+
+type (
+	// Handler - processes a claimed job. Returning an error (or panicking) fails the attempt.
+	Handler func(ctx context.Context, job *Job) error
+
+	WorkerOptions struct {
+		// Number of jobs processed at the same time. Defaults to 1.
+		Concurrency int
+
+		// How long an idle worker waits before looking for new jobs. Defaults to DEFAULT_POLL_INTERVAL.
+		PollInterval time.Duration
+
+		// Called with errors that do not belong to a handler, i.e. failing to claim or complete a job.
+		// The job is nil when the error happened while claiming one.
+		OnError func(job *Job, err error)
+	}
+)
+
+// Work - claims and processes jobs with a pool of workers until the context is cancelled.
+//
+// Once the context is cancelled, workers stop claiming jobs but finish the ones they are running,
+// so Work returns nil after every in-flight job has been completed or failed. Handlers receive a
+// context that is not cancelled with `ctx`, but that expires with the visibility timeout.
+//
+// Usage:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//
+//	err := queue.Work(ctx, func(ctx context.Context, job *jobs.Job) error {
+//		var email Email
+//		if err := job.Decode(&email); err != nil {
+//			return err
+//		}
+//
+//		return send(ctx, email)
+//	}, jobs.WorkerOptions{Concurrency: 4})
+func (q *Queue) Work(ctx context.Context, handler Handler, options WorkerOptions) error {
+	if handler == nil {
+		return fmt.Errorf("no handler provided")
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = DEFAULT_POLL_INTERVAL
+	}
+
+	if options.OnError == nil {
+		options.OnError = func(*Job, error) {}
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			q.work(ctx, handler, options)
+		}()
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (q *Queue) work(ctx context.Context, handler Handler, options WorkerOptions) {
+	for ctx.Err() == nil {
+		job, err := q.Dequeue(ctx)
+		if err == nil {
+			q.process(ctx, job, handler, options)
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !errors.Is(err, ErrNoJob) {
+			options.OnError(nil, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(options.PollInterval):
+		}
+	}
+}
+
+// process - runs the handler on a claimed job and records the outcome.
+func (q *Queue) process(ctx context.Context, job *Job, handler Handler, options WorkerOptions) {
+	ctx = context.WithoutCancel(ctx)
+
+	handlerCtx, cancel := context.WithTimeout(ctx, q.options.VisibilityTimeout)
+	defer cancel()
+
+	if err := run(handlerCtx, job, handler); err != nil {
+		if err := q.Fail(ctx, job, err); err != nil {
+			options.OnError(job, err)
+		}
+
+		return
+	}
+
+	if err := q.Complete(ctx, job); err != nil {
+		options.OnError(job, err)
+	}
+}
+
+func run(ctx context.Context, job *Job, handler Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
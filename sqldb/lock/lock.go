@@ -0,0 +1,327 @@
+// Package lock implements named locks shared by every process using the same database,
+// so that work such as migrations or scheduled jobs runs on a single replica at a time.
+//
+// On Postgres, locks are session-level advisory locks (`pg_advisory_lock`), held by a connection
+// taken out of the pool while the locked function runs. They are released when it returns, or by
+// the server if the holder's connection is lost. No transaction stays open, so the lock is not
+// subject to `idle_in_transaction_session_timeout`. The server still drops it if the connection is
+// closed in the meantime, i.e. after a network failure or by `idle_session_timeout`, while the
+// function keeps running. WithLock then reports ErrLockLost once the function returns, so work
+// that must never overlap should also be guarded by the data it changes.
+//
+// On SQLite, locks are leases recorded in a table. A lease expires unless its holder keeps renewing it,
+// so a lock held by a crashed process becomes available again after the lease TTL.
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+const (
+	DEFAULT_TABLE_NAME     = "locks"
+	DEFAULT_LEASE_TTL      = 30 * time.Second
+	DEFAULT_RETRY_INTERVAL = 250 * time.Millisecond
+)
+
+var (
+	ErrLockHeld = errors.New("lock held by another owner")
+
+	// Returned when a SQLite lease could not be renewed, or the Postgres connection holding the lock was lost,
+	// while the locked function ran, meaning another owner may have acquired the lock in the meantime.
+	ErrLockLost = errors.New("lock lease lost")
+)
+
+type (
+	Locker struct {
+		db      sqldb.SqlBackend
+		adapter sqldb.SQLAdapter
+		table   string
+		options Options
+
+		now func() time.Time
+	}
+
+	// Implemented by *sql.DB, which can hand out a dedicated connection.
+	connector interface {
+		Conn(context.Context) (*sql.Conn, error)
+	}
+
+	Options struct {
+		// The database engine holding the locks. Must be one of sqldb.PostgreSQL or sqldb.SQLite3.
+		Adapter sqldb.SQLAdapter
+
+		// Name of the table storing SQLite leases. Defaults to `locks`.
+		TableName string
+
+		// How long a SQLite lease lasts without being renewed. Leases are renewed every third of it.
+		// Defaults to DEFAULT_LEASE_TTL.
+		TTL time.Duration
+
+		// How often WithLock tries to take a SQLite lease held by another owner. Defaults to DEFAULT_RETRY_INTERVAL.
+		RetryInterval time.Duration
+	}
+)
+
+// New - returns a Locker for the given database.
+//
+// Usage:
+//
+//	locker, err := lock.New(db, lock.Options{Adapter: sqldb.SQLite3})
+//	err = locker.Init(ctx)
+//
+//	err = locker.WithLock(ctx, "migrations", func() error {
+//		_, err := migrator.Up(ctx, 0)
+//		return err
+//	})
+func New(db sqldb.SqlBackend, options Options) (*Locker, error) {
+	if db == nil {
+		return nil, fmt.Errorf("no database provided")
+	}
+
+	if options.Adapter != sqldb.PostgreSQL && options.Adapter != sqldb.SQLite3 {
+		return nil, fmt.Errorf("unsupported adapter: %q", options.Adapter)
+	}
+
+	table := options.TableName
+	if table == "" {
+		table = DEFAULT_TABLE_NAME
+	}
+
+	if !sqldb.IdentifierValidationPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid locks table name: %q", table)
+	}
+
+	if options.TTL <= 0 {
+		options.TTL = DEFAULT_LEASE_TTL
+	}
+
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = DEFAULT_RETRY_INTERVAL
+	}
+
+	return &Locker{db: db, adapter: options.Adapter, table: table, options: options, now: time.Now}, nil
+}
+
+// Init - creates the table storing SQLite leases if it does not exist yet. Postgres needs no table.
+func (l *Locker) Init(ctx context.Context) error {
+	if l.adapter == sqldb.PostgreSQL {
+		return nil
+	}
+
+	_, err := l.db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, owner TEXT NOT NULL, expires_at BIGINT NOT NULL)`,
+		l.table,
+	))
+
+	return err
+}
+
+// WithLock - waits until the named lock is free, then holds it while `fn` runs.
+//
+// Returns the context's error if it is done before the lock is acquired. Once acquired,
+// the lock is held until `fn` returns, even if the context is cancelled in the meantime.
+func (l *Locker) WithLock(ctx context.Context, name string, fn func() error) error {
+	return l.run(ctx, name, true, fn)
+}
+
+// TryWithLock - runs `fn` while holding the named lock if it is free, or returns ErrLockHeld without running it.
+//
+// Usage:
+//
+//	err := locker.TryWithLock(ctx, "nightly-report", report)
+//	if errors.Is(err, lock.ErrLockHeld) {
+//		return nil // Another replica is running it.
+//	}
+func (l *Locker) TryWithLock(ctx context.Context, name string, fn func() error) error {
+	return l.run(ctx, name, false, fn)
+}
+
+func (l *Locker) run(ctx context.Context, name string, wait bool, fn func() error) error {
+	if name == "" {
+		return fmt.Errorf("no lock name provided")
+	}
+
+	if l.adapter == sqldb.PostgreSQL {
+		return l.advisory(ctx, name, wait, fn)
+	}
+
+	return l.lease(ctx, name, wait, fn)
+}
+
+// advisory - holds a Postgres advisory lock on a dedicated connection until `fn` returns.
+func (l *Locker) advisory(ctx context.Context, name string, wait bool, fn func() error) error {
+	pool, ok := l.db.(connector)
+	if !ok {
+		return fmt.Errorf("postgres locks require a database handing out dedicated connections, such as *sql.DB")
+	}
+
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := Key(name)
+
+	if wait {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, key); err != nil {
+			discard(conn)
+			return err
+		}
+	} else {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+			discard(conn)
+			return err
+		}
+
+		if !acquired {
+			return fmt.Errorf("%w: %s", ErrLockHeld, name)
+		}
+	}
+
+	err = fn()
+
+	var released bool
+	if unlockErr := conn.QueryRowContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, key).Scan(&released); unlockErr != nil {
+		discard(conn)
+		return errors.Join(err, fmt.Errorf("%w: %s: %v", ErrLockLost, name, unlockErr))
+	}
+
+	if !released {
+		return errors.Join(err, fmt.Errorf("%w: %s", ErrLockLost, name))
+	}
+
+	return err
+}
+
+// discard - closes the connection instead of returning it to the pool, as it may still hold a lock.
+func discard(conn *sql.Conn) {
+	conn.Raw(func(any) error { return driver.ErrBadConn })
+}
+
+// lease - holds a SQLite lease, renewing it until `fn` returns.
+func (l *Locker) lease(ctx context.Context, name string, wait bool, fn func() error) error {
+	owner := uuid.NewString()
+
+	for {
+		acquired, err := l.acquire(ctx, name, owner)
+		if err != nil {
+			return err
+		}
+
+		if acquired {
+			break
+		}
+
+		if !wait {
+			return fmt.Errorf("%w: %s", ErrLockHeld, name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.options.RetryInterval):
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	var (
+		wg   sync.WaitGroup
+		lost error
+	)
+
+	stop := make(chan struct{})
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(l.options.TTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := l.renew(ctx, name, owner); err != nil {
+					lost = err
+					return
+				}
+			}
+		}
+	}()
+
+	err := fn()
+
+	close(stop)
+	wg.Wait()
+
+	if _, releaseErr := l.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE name = ? AND owner = ?`, l.table),
+		name, owner,
+	); releaseErr != nil {
+		err = errors.Join(err, releaseErr)
+	}
+
+	return errors.Join(err, lost)
+}
+
+// acquire - takes the lease if nobody holds it or the current lease expired.
+func (l *Locker) acquire(ctx context.Context, name, owner string) (bool, error) {
+	now := l.now()
+
+	result, err := l.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (name, owner, expires_at) VALUES (?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+			WHERE expires_at <= ?`,
+			l.table,
+		),
+		name, owner, now.Add(l.options.TTL).UnixMilli(), now.UnixMilli(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (l *Locker) renew(ctx context.Context, name, owner string) error {
+	result, err := l.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`UPDATE %s SET expires_at = ? WHERE name = ? AND owner = ?`, l.table),
+		l.now().Add(l.options.TTL).UnixMilli(), name, owner,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrLockLost, name, err)
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return fmt.Errorf("%w: %s", ErrLockLost, name)
+	}
+
+	return nil
+}
+
+// Key - returns the Postgres advisory lock key used for the named lock.
+func Key(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
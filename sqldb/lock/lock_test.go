@@ -0,0 +1,174 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func TestLocker_TryWithLock(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+
+	tests := []struct {
+		name    string
+		adapter sqldb.SQLAdapter
+		expect  func(db *sqltest.Backend)
+		wantRun bool
+		wantErr error
+	}{
+		{
+			name:    "sqlite lease acquired",
+			adapter: sqldb.SQLite3,
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^INSERT INTO locks .* ON CONFLICT \(name\) DO UPDATE .* WHERE expires_at <= \?$`).
+					WithArgs("reports", sqltest.AnyArg, now.Add(DEFAULT_LEASE_TTL).UnixMilli(), now.UnixMilli()).
+					WillReturnResult(0, 1)
+				db.ExpectExec(`^DELETE FROM locks WHERE name = \? AND owner = \?$`).WithArgs("reports", sqltest.AnyArg)
+			},
+			wantRun: true,
+		},
+		{
+			name:    "sqlite lease held",
+			adapter: sqldb.SQLite3,
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^INSERT INTO locks`).WillReturnResult(0, 0)
+			},
+			wantErr: ErrLockHeld,
+		},
+		{
+			name:    "postgres lock acquired",
+			adapter: sqldb.PostgreSQL,
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SELECT pg_try_advisory_lock\(\$1\)$`).WithArgs(Key("reports")).WillReturnRows([]string{"locked"}, []any{true})
+				db.ExpectQuery(`^SELECT pg_advisory_unlock\(\$1\)$`).WithArgs(Key("reports")).WillReturnRows([]string{"unlocked"}, []any{true})
+			},
+			wantRun: true,
+		},
+		{
+			name:    "postgres lock held",
+			adapter: sqldb.PostgreSQL,
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SELECT pg_try_advisory_lock\(\$1\)$`).WithArgs(Key("reports")).WillReturnRows([]string{"locked"}, []any{false})
+			},
+			wantErr: ErrLockHeld,
+		},
+		{
+			name:    "postgres lock lost",
+			adapter: sqldb.PostgreSQL,
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SELECT pg_try_advisory_lock\(\$1\)$`).WillReturnRows([]string{"locked"}, []any{true})
+				db.ExpectQuery(`^SELECT pg_advisory_unlock\(\$1\)$`).WillReturnRows([]string{"unlocked"}, []any{false})
+			},
+			wantRun: true,
+			wantErr: ErrLockLost,
+		},
+		{
+			name:    "postgres connection lost",
+			adapter: sqldb.PostgreSQL,
+			expect: func(db *sqltest.Backend) {
+				db.ExpectQuery(`^SELECT pg_try_advisory_lock\(\$1\)$`).WillReturnRows([]string{"locked"}, []any{true})
+				db.ExpectQuery(`^SELECT pg_advisory_unlock\(\$1\)$`).WillReturnError(errors.New("conn closed"))
+			},
+			wantRun: true,
+			wantErr: ErrLockLost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			tt.expect(db)
+
+			locker, err := New(db, Options{Adapter: tt.adapter})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			locker.now = func() time.Time { return now }
+
+			ran := false
+			err = locker.TryWithLock(context.Background(), "reports", func() error {
+				ran = true
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TryWithLock() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if ran != tt.wantRun {
+				t.Errorf("TryWithLock() ran = %v, want %v", ran, tt.wantRun)
+			}
+		})
+	}
+}
+
+func TestLocker_WithLock_cancelled(t *testing.T) {
+	db := sqltest.New(t)
+	db.ExpectExec(`^INSERT INTO locks`).WillReturnResult(0, 0)
+
+	locker, _ := New(db, Options{Adapter: sqldb.SQLite3, RetryInterval: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := locker.WithLock(ctx, "reports", func() error {
+		t.Error("WithLock() ran while the lock was held")
+		return nil
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WithLock() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLocker_WithLock_postgres(t *testing.T) {
+	db := sqltest.New(t)
+	db.ExpectExec(`^SELECT pg_advisory_lock\(\$1\)$`).WithArgs(Key("migrations"))
+	db.ExpectQuery(`^SELECT pg_advisory_unlock\(\$1\)$`).WithArgs(Key("migrations")).WillReturnRows([]string{"unlocked"}, []any{true})
+
+	locker, _ := New(db, Options{Adapter: sqldb.PostgreSQL})
+
+	errRun := errors.New("migration failed")
+
+	err := locker.WithLock(context.Background(), "migrations", func() error {
+		// The lock is held by a session rather than a transaction left open while `fn` runs.
+		for _, call := range db.Calls() {
+			if call.Kind == sqltest.BEGIN {
+				t.Errorf("WithLock() began a transaction to hold the lock")
+			}
+		}
+
+		return errRun
+	})
+
+	if !errors.Is(err, errRun) {
+		t.Errorf("WithLock() error = %v, want %v", err, errRun)
+	}
+}
+
+func TestLocker_WithLock_cluster(t *testing.T) {
+	primary, replica := sqltest.New(t), sqltest.New(t)
+	primary.ExpectExec(`^SELECT pg_advisory_lock\(\$1\)$`).WithArgs(Key("migrations"))
+	primary.ExpectQuery(`^SELECT pg_advisory_unlock\(\$1\)$`).WithArgs(Key("migrations")).WillReturnRows([]string{"unlocked"}, []any{true})
+
+	cluster := sqldb.NewCluster(primary, []sqldb.SqlBackend{replica}, sqldb.ClusterOptions{})
+	defer cluster.Close()
+
+	locker, err := New(cluster, Options{Adapter: sqldb.PostgreSQL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ran := false
+	if err := locker.WithLock(context.Background(), "migrations", func() error {
+		ran = true
+		return nil
+	}); err != nil || !ran {
+		t.Errorf("WithLock() error = %v, ran = %v, want the lock held on the primary", err, ran)
+	}
+}
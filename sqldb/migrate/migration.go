@@ -0,0 +1,227 @@
+// Package migrate applies and reverts timestamped SQL migrations against a sqldb.SqlBackend.
+//
+// Migrations are plain SQL files named after the pattern:
+//
+//	<version>_<name>.up.sql
+//	<version>_<name>.down.sql
+//
+// where `version` is a 20-digit timestamp (see cli.VersionValidationPattern) and `name`
+// follows cli.NameValidationPattern. For example:
+//
+//	20240101120000000000_create_users.up.sql
+//	20240101120000000000_create_users.down.sql
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/cli"
+)
+
+type (
+	Migration struct {
+		Version string `json:"version" yaml:"version"`
+		Name    string `json:"name" yaml:"name"`
+		Up      string `json:"-" yaml:"-"`
+		Down    string `json:"-" yaml:"-"`
+	}
+
+	Direction string
+)
+
+const (
+	UP   Direction = "up"
+	DOWN Direction = "down"
+)
+
+var (
+	ErrMigrationNotFound = errors.New("migration not found")
+	ErrIrreversible      = errors.New("migration has no down script")
+
+	filenamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+// Load - reads all migration files at the root of `source`.
+//
+// Use os.DirFS for migrations on disk or fs.Sub for an embedded directory.
+// Files not ending in `.up.sql` or `.down.sql` are ignored. Every migration needs an up script,
+// though it may be empty, as written by Create; applying an empty script fails.
+//
+// Usage:
+//
+//	//go:embed migrations/*.sql
+//	var files embed.FS
+//
+//	source, _ := fs.Sub(files, "migrations")
+//	migrations, err := Load(source)
+func Load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]*Migration{}
+	scripts := map[string]bool{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		version, name, direction, err := ParseFilename(entry.Name())
+		if err != nil {
+			if strings.HasSuffix(entry.Name(), ".sql") {
+				return nil, err
+			}
+
+			continue
+		}
+
+		data, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := index[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			index[version] = m
+		}
+
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %s has conflicting names: %s and %s", version, m.Name, name)
+		}
+
+		scripts[entry.Name()] = true
+
+		switch direction {
+		case UP:
+			m.Up = string(data)
+		case DOWN:
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(index))
+	for _, m := range index {
+		if !scripts[m.Filename(UP)] {
+			return nil, fmt.Errorf("migration %s_%s has no up script", m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// ParseFilename - extracts the version, name and direction of a migration file.
+//
+// Usage:
+//
+//	ParseFilename("20240101120000000000_create_users.up.sql") // -> "20240101120000000000", "create_users", UP
+func ParseFilename(filename string) (version, name string, direction Direction, err error) {
+	parts := filenamePattern.FindStringSubmatch(filename)
+	if parts == nil {
+		return "", "", "", fmt.Errorf("invalid migration filename: %s", filename)
+	}
+
+	version, name, direction = parts[1], parts[2], Direction(parts[3])
+
+	if !cli.VersionValidationPattern.MatchString(version) {
+		return "", "", "", fmt.Errorf("invalid migration version in %s", filename)
+	}
+
+	if !cli.NameValidationPattern.MatchString(name) {
+		return "", "", "", fmt.Errorf("invalid migration name in %s", filename)
+	}
+
+	return version, name, direction, nil
+}
+
+// Filename - returns the name of the file holding the script for the given direction.
+func (m Migration) Filename(direction Direction) string {
+	return fmt.Sprintf("%s_%s.%s.sql", m.Version, m.Name, direction)
+}
+
+// Matches - returns true if the migration is identified by the given flag.
+func (m Migration) Matches(flag cli.MigrationFilterFlag) bool {
+	switch flag.Type {
+	case "version":
+		return m.Version == flag.Value
+	case "name":
+		return m.Name == flag.Value
+	}
+
+	return false
+}
+
+// find - returns the position of the migration identified by `flag`.
+func find(migrations []Migration, flag cli.MigrationFilterFlag) (int, error) {
+	position := -1
+
+	for i, m := range migrations {
+		if !m.Matches(flag) {
+			continue
+		}
+
+		if position >= 0 {
+			return -1, fmt.Errorf("migration %s is ambiguous", flag.Value)
+		}
+
+		position = i
+	}
+
+	if position < 0 {
+		return -1, fmt.Errorf("%w: %s", ErrMigrationNotFound, flag.Value)
+	}
+
+	return position, nil
+}
+
+// NewVersion - returns the 20-digit version for a migration created at `t`.
+//
+// Usage:
+//
+//	NewVersion(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) // -> "20240101120000000000"
+func NewVersion(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%s%06d", t.Format("20060102150405"), t.Nanosecond()/int(time.Microsecond))
+}
+
+// Create - writes empty up and down scripts for a new migration into `directory`.
+func Create(directory, name string) (Migration, error) {
+	if !cli.NameValidationPattern.MatchString(name) {
+		return Migration{}, fmt.Errorf("invalid migration name: %s", name)
+	}
+
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return Migration{}, err
+	}
+
+	migration := Migration{Version: NewVersion(time.Now()), Name: name}
+
+	for _, direction := range []Direction{UP, DOWN} {
+		path := filepath.Join(directory, migration.Filename(direction))
+
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return migration, err
+		}
+
+		if err := file.Close(); err != nil {
+			return migration, err
+		}
+	}
+
+	return migration, nil
+}
@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/cli"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name:   "empty source",
+			source: fstest.MapFS{},
+			want:   []Migration{},
+		},
+		{
+			name: "sorted by version",
+			source: fstest.MapFS{
+				"20240102000000000000_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+				"20240101000000000000_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT);")},
+				"20240101000000000000_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+				"README.md": {Data: []byte("ignored")},
+			},
+			want: []Migration{
+				{Version: "20240101000000000000", Name: "create_users", Up: "CREATE TABLE users (id INT);", Down: "DROP TABLE users;"},
+				{Version: "20240102000000000000", Name: "add_email", Up: "ALTER TABLE users ADD email TEXT;"},
+			},
+		},
+		{
+			name: "empty up script",
+			source: fstest.MapFS{
+				"20240101000000000000_create_users.up.sql":   {Data: []byte{}},
+				"20240101000000000000_create_users.down.sql": {Data: []byte{}},
+			},
+			want: []Migration{{Version: "20240101000000000000", Name: "create_users"}},
+		},
+		{
+			name: "missing up script",
+			source: fstest.MapFS{
+				"20240101000000000000_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			source: fstest.MapFS{
+				"20240101000000000000_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id INT);")},
+				"20240101000000000000_create_people.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid version",
+			source: fstest.MapFS{
+				"2024_create_users.up.sql": {Data: []byte("CREATE TABLE users (id INT);")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		filename      string
+		wantVersion   string
+		wantName      string
+		wantDirection Direction
+		wantErr       bool
+	}{
+		{filename: "20240101120000000000_create_users.up.sql", wantVersion: "20240101120000000000", wantName: "create_users", wantDirection: UP},
+		{filename: "20240101120000000000_create_users.down.sql", wantVersion: "20240101120000000000", wantName: "create_users", wantDirection: DOWN},
+		{filename: "20240101120000000000_create_users.sql", wantErr: true},
+		{filename: "20240101120000000000_create_users_2.up.sql", wantErr: true},
+		{filename: "202401011200_create_users.up.sql", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			version, name, direction, err := ParseFilename(tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilename() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if version != tt.wantVersion || name != tt.wantName || direction != tt.wantDirection {
+				t.Errorf("ParseFilename() = %v, %v, %v, want %v, %v, %v", version, name, direction, tt.wantVersion, tt.wantName, tt.wantDirection)
+			}
+		})
+	}
+}
+
+func Test_find(t *testing.T) {
+	migrations := []Migration{
+		{Version: "20240101000000000000", Name: "create_users"},
+		{Version: "20240102000000000000", Name: "add_email"},
+		{Version: "20240103000000000000", Name: "add_email"},
+	}
+
+	tests := []struct {
+		name    string
+		flag    cli.MigrationFilterFlag
+		want    int
+		wantErr bool
+	}{
+		{name: "by version", flag: cli.MigrationFilterFlag{Value: "20240102000000000000", Type: "version"}, want: 1},
+		{name: "by name", flag: cli.MigrationFilterFlag{Value: "create_users", Type: "name"}, want: 0},
+		{name: "ambiguous name", flag: cli.MigrationFilterFlag{Value: "add_email", Type: "name"}, want: -1, wantErr: true},
+		{name: "unknown version", flag: cli.MigrationFilterFlag{Value: "20250101000000000000", Type: "version"}, want: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := find(migrations, tt.flag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("find() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewVersion(t *testing.T) {
+	got := NewVersion(time.Date(2024, 1, 2, 3, 4, 5, 6007000, time.UTC))
+	if got != "20240102030405006007" {
+		t.Errorf("NewVersion() = %v, want %v", got, "20240102030405006007")
+	}
+
+	if !cli.VersionValidationPattern.MatchString(got) {
+		t.Errorf("NewVersion() = %v, does not match the version pattern", got)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	directory := t.TempDir()
+
+	migration, err := Create(directory, "create_users")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	migrations, err := Load(os.DirFS(directory))
+	if err != nil || len(migrations) != 1 || migrations[0].Version != migration.Version {
+		t.Fatalf("Load() = %v, %v, want the created migration", migrations, err)
+	}
+
+	for _, direction := range []Direction{UP, DOWN} {
+		if _, err := os.Stat(filepath.Join(directory, migration.Filename(direction))); err != nil {
+			t.Errorf("Create() did not write %v: %v", migration.Filename(direction), err)
+		}
+	}
+
+	if _, err := Create(directory, "create-users"); err == nil {
+		t.Errorf("Create() expected error for invalid name")
+	}
+}
@@ -0,0 +1,430 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+const DEFAULT_TABLE_NAME = "schema_migrations"
+
+type (
+	Migrator struct {
+		db         sqldb.SqlBackend
+		adapter    sqldb.SQLAdapter
+		table      string
+		migrations []Migration
+	}
+
+	Options struct {
+		// The database engine the migrations target. Must be one of sqldb.PostgreSQL or sqldb.SQLite3.
+		Adapter sqldb.SQLAdapter
+
+		// Name of the table tracking applied versions. Defaults to `schema_migrations`.
+		TableName string
+	}
+
+	MigrationStatus struct {
+		Migration `yaml:",inline"`
+		Applied   bool       `json:"applied" yaml:"applied"`
+		AppliedAt *time.Time `json:"applied_at,omitempty" yaml:"applied_at,omitempty"`
+
+		// Set when the version is recorded in the database but its files are absent from the source.
+		Missing bool `json:"missing,omitempty" yaml:"missing,omitempty"`
+	}
+
+	Result struct {
+		Migration `yaml:",inline"`
+		Direction Direction     `json:"direction" yaml:"direction"`
+		Duration  time.Duration `json:"duration" yaml:"duration"`
+	}
+)
+
+// New - returns a Migrator for the migrations found in `source`.
+//
+// Usage:
+//
+//	m, err := New(db, os.DirFS("db/migrations"), Options{Adapter: sqldb.PostgreSQL})
+//	results, err := m.Up(ctx, 0)
+func New(db sqldb.SqlBackend, source fs.FS, options Options) (*Migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithMigrations(db, migrations, options)
+}
+
+// NewWithMigrations - returns a Migrator for an already loaded set of migrations.
+func NewWithMigrations(db sqldb.SqlBackend, migrations []Migration, options Options) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("no database provided")
+	}
+
+	if options.Adapter != sqldb.PostgreSQL && options.Adapter != sqldb.SQLite3 {
+		return nil, fmt.Errorf("unsupported adapter: %q", options.Adapter)
+	}
+
+	table := options.TableName
+	if table == "" {
+		table = DEFAULT_TABLE_NAME
+	}
+
+	if !sqldb.IdentifierValidationPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid migrations table name: %q", table)
+	}
+
+	sorted := append([]Migration{}, migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Migrator{db: db, adapter: options.Adapter, table: table, migrations: sorted}, nil
+}
+
+// Migrations - returns all known migrations in version order.
+func (m *Migrator) Migrations() []Migration { return append([]Migration{}, m.migrations...) }
+
+// Init - creates the table tracking applied versions if it does not exist yet.
+func (m *Migrator) Init(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (version VARCHAR(20) PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL)`,
+		m.table,
+	))
+
+	return err
+}
+
+// Status - returns every known migration along with whether it has been applied.
+// Versions recorded in the database but absent from the source are reported as missing.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := map[string]bool{}
+
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Migration: migration}
+
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = helpers.PointerTo(record.AppliedAt)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for version, record := range applied {
+		if known[version] {
+			continue
+		}
+
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: version, Name: record.Name},
+			Applied:   true,
+			AppliedAt: helpers.PointerTo(record.AppliedAt),
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Up - applies the next `steps` pending migrations. A value of `steps` < 1 applies all of them.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Result, error) {
+	pending, err := m.pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.run(ctx, limit(pending, steps), UP)
+}
+
+// UpTo - applies all pending migrations up to and including the target.
+func (m *Migrator) UpTo(ctx context.Context, target cli.MigrationFilterFlag) ([]Result, error) {
+	position, err := find(m.migrations, target)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := m.pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version := m.migrations[position].Version
+	pending = helpers.Filter(pending, func(_ int, p Migration) bool { return p.Version <= version })
+
+	return m.run(ctx, pending, UP)
+}
+
+// Down - reverts the last `steps` applied migrations. A value of `steps` < 1 reverts all of them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Result, error) {
+	applied, err := m.reversible(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.run(ctx, limit(applied, steps), DOWN)
+}
+
+// DownTo - reverts all applied migrations down to and including the target.
+func (m *Migrator) DownTo(ctx context.Context, target cli.MigrationFilterFlag) ([]Result, error) {
+	position, err := find(m.migrations, target)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.reversible(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version := m.migrations[position].Version
+	applied = helpers.Filter(applied, func(_ int, a Migration) bool { return a.Version >= version })
+
+	return m.run(ctx, applied, DOWN)
+}
+
+// To - moves the database to the target migration, reverting every migration
+// applied after it and applying every pending migration up to and including it.
+func (m *Migrator) To(ctx context.Context, target cli.MigrationFilterFlag) ([]Result, error) {
+	position, err := find(m.migrations, target)
+	if err != nil {
+		return nil, err
+	}
+
+	version := m.migrations[position].Version
+
+	applied, err := m.reversible(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied = helpers.Filter(applied, func(_ int, a Migration) bool { return a.Version > version })
+
+	reverted, err := m.run(ctx, applied, DOWN)
+	if err != nil {
+		return reverted, err
+	}
+
+	pending, err := m.pending(ctx)
+	if err != nil {
+		return reverted, err
+	}
+
+	pending = helpers.Filter(pending, func(_ int, p Migration) bool { return p.Version <= version })
+
+	migrated, err := m.run(ctx, pending, UP)
+
+	return append(reverted, migrated...), err
+}
+
+// Redo - reverts and re-applies the last `steps` applied migrations. A value of `steps` < 1 redoes all of them.
+func (m *Migrator) Redo(ctx context.Context, steps int) ([]Result, error) {
+	applied, err := m.reversible(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied = limit(applied, steps)
+
+	reverted, err := m.run(ctx, applied, DOWN)
+	if err != nil {
+		return reverted, err
+	}
+
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Version < applied[j].Version })
+
+	migrated, err := m.run(ctx, applied, UP)
+
+	return append(reverted, migrated...), err
+}
+
+type record struct {
+	Name      string
+	AppliedAt time.Time
+}
+
+// applied - returns the versions recorded in the migrations table.
+func (m *Migrator) applied(ctx context.Context) (map[string]record, error) {
+	if err := m.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf(`SELECT version, name, applied_at FROM %s`, m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := map[string]record{}
+
+	for rows.Next() {
+		var version, name string
+		var appliedAt any
+
+		if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		t, err := parseTime(appliedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		records[version] = record{Name: name, AppliedAt: t}
+	}
+
+	return records, rows.Err()
+}
+
+// pending - returns the migrations not yet applied, oldest first.
+func (m *Migrator) pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.Filter(m.migrations, func(_ int, migration Migration) bool {
+		_, ok := applied[migration.Version]
+		return !ok
+	}), nil
+}
+
+// reversible - returns the applied migrations, newest first.
+func (m *Migrator) reversible(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+
+	migrations := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		position, err := find(m.migrations, cli.MigrationFilterFlag{Value: version, Type: "version"})
+		if err != nil {
+			// Without its files, the migration can only be reported as irreversible once reached.
+			migrations = append(migrations, Migration{Version: version, Name: applied[version].Name})
+			continue
+		}
+
+		migrations = append(migrations, m.migrations[position])
+	}
+
+	return migrations, nil
+}
+
+// run - executes each migration in its own transaction, stopping at the first failure.
+func (m *Migrator) run(ctx context.Context, migrations []Migration, direction Direction) ([]Result, error) {
+	results := make([]Result, 0, len(migrations))
+
+	for _, migration := range migrations {
+		start := time.Now()
+
+		if err := m.execute(ctx, migration, direction); err != nil {
+			return results, fmt.Errorf("%s: %w", migration.Filename(direction), err)
+		}
+
+		results = append(results, Result{Migration: migration, Direction: direction, Duration: time.Since(start)})
+	}
+
+	return results, nil
+}
+
+func (m *Migrator) execute(ctx context.Context, migration Migration, direction Direction) error {
+	script := migration.Up
+	if direction == DOWN {
+		script = migration.Down
+	}
+
+	if strings.TrimSpace(script) == "" {
+		if direction == DOWN {
+			return ErrIrreversible
+		}
+
+		return fmt.Errorf("empty migration script")
+	}
+
+	placeholders := helpers.EnumerateArgs(3, func(index, _ int) string { return m.adapter.Placeholder(index) })
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if direction == UP {
+		_, err = tx.ExecContext(
+			ctx,
+			fmt.Sprintf(`INSERT INTO %s (version, name, applied_at) VALUES (%s)`, m.table, placeholders),
+			migration.Version, migration.Name, time.Now().UTC(),
+		)
+	} else {
+		_, err = tx.ExecContext(
+			ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE version = %s`, m.table, m.adapter.Placeholder(1)),
+			migration.Version,
+		)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func limit(migrations []Migration, steps int) []Migration {
+	if steps < 1 || steps > len(migrations) {
+		return migrations
+	}
+
+	return migrations[:steps]
+}
+
+// parseTime - normalizes the timestamp representations returned by the supported drivers.
+func parseTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		return parseTime(string(v))
+	case string:
+		for _, layout := range []string{
+			"2006-01-02 15:04:05.999999999-07:00",
+			"2006-01-02T15:04:05.999999999-07:00",
+			"2006-01-02 15:04:05.999999999",
+			"2006-01-02T15:04:05.999999999",
+			time.RFC3339Nano,
+		} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported timestamp value: %v", value)
+}
@@ -0,0 +1,230 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/cli"
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+var testMigrations = []Migration{
+	{Version: "20240101000000000000", Name: "create_a", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a"},
+	{Version: "20240102000000000000", Name: "create_b", Up: "CREATE TABLE b (id INT)", Down: "DROP TABLE b"},
+	{Version: "20240103000000000000", Name: "create_c", Up: "CREATE TABLE c (id INT)"},
+}
+
+// expectMigrations - scripts the statements of a Migrator: one lookup of the applied versions per entry
+// of `lookups`, then the script and the bookkeeping statement of every step, i.e. "up 20240101000000000000".
+func expectMigrations(db *sqltest.Backend, lookups [][]string, steps []string) {
+	db.ExpectExec(`^CREATE TABLE IF NOT EXISTS schema_migrations `).Times(len(lookups))
+
+	for _, versions := range lookups {
+		rows := [][]any{}
+		for _, version := range versions {
+			migration := testMigrations[position(version)]
+			rows = append(rows, []any{migration.Version, migration.Name, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+		}
+
+		db.ExpectQuery(`^SELECT version, name, applied_at FROM schema_migrations$`).
+			WillReturnRows([]string{"version", "name", "applied_at"}, rows...)
+	}
+
+	for _, step := range steps {
+		direction, version, _ := strings.Cut(step, " ")
+		migration := testMigrations[position(version)]
+
+		if Direction(direction) == UP {
+			db.ExpectExec(regexp.QuoteMeta(migration.Up))
+			db.ExpectExec(`^INSERT INTO schema_migrations \(version, name, applied_at\) VALUES \(\?, \?, \?\)$`).
+				WithArgs(migration.Version, migration.Name, sqltest.AnyArg)
+		} else {
+			db.ExpectExec(regexp.QuoteMeta(migration.Down))
+			db.ExpectExec(`^DELETE FROM schema_migrations WHERE version = \?$`).WithArgs(migration.Version)
+		}
+	}
+}
+
+func position(version string) int {
+	for i, m := range testMigrations {
+		if m.Version == version {
+			return i
+		}
+	}
+
+	panic("unknown test migration " + version)
+}
+
+func TestMigrator(t *testing.T) {
+	const v1, v2, v3 = "20240101000000000000", "20240102000000000000", "20240103000000000000"
+
+	errScript := errors.New("syntax error")
+
+	tests := []struct {
+		name    string
+		lookups [][]string
+		run     func(ctx context.Context, m *Migrator) ([]Result, error)
+		fail    string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "up all",
+			lookups: [][]string{{}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Up(ctx, 0) },
+			want:    []string{"up " + v1, "up " + v2, "up " + v3},
+		},
+		{
+			name:    "up one step",
+			lookups: [][]string{{v1}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Up(ctx, 1) },
+			want:    []string{"up " + v2},
+		},
+		{
+			name:    "down all",
+			lookups: [][]string{{v1, v2}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Down(ctx, -1) },
+			want:    []string{"down " + v2, "down " + v1},
+		},
+		{
+			name:    "down one step",
+			lookups: [][]string{{v1, v2}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Down(ctx, 1) },
+			want:    []string{"down " + v2},
+		},
+		{
+			name:    "down irreversible",
+			lookups: [][]string{{v1, v2, v3}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Down(ctx, 1) },
+			wantErr: ErrIrreversible,
+		},
+		{
+			name:    "up to",
+			lookups: [][]string{{}},
+			run: func(ctx context.Context, m *Migrator) ([]Result, error) {
+				return m.UpTo(ctx, cli.MigrationFilterFlag{Value: "create_b", Type: "name"})
+			},
+			want: []string{"up " + v1, "up " + v2},
+		},
+		{
+			name:    "down to",
+			lookups: [][]string{{v1, v2}},
+			run: func(ctx context.Context, m *Migrator) ([]Result, error) {
+				return m.DownTo(ctx, cli.MigrationFilterFlag{Value: v2, Type: "version"})
+			},
+			want: []string{"down " + v2},
+		},
+		{
+			name:    "to an older version",
+			lookups: [][]string{{v1, v2}, {v1}},
+			run: func(ctx context.Context, m *Migrator) ([]Result, error) {
+				return m.To(ctx, cli.MigrationFilterFlag{Value: "create_a", Type: "name"})
+			},
+			want: []string{"down " + v2},
+		},
+		{
+			name:    "to a newer version",
+			lookups: [][]string{{v1}, {v1}},
+			run: func(ctx context.Context, m *Migrator) ([]Result, error) {
+				return m.To(ctx, cli.MigrationFilterFlag{Value: v2, Type: "version"})
+			},
+			want: []string{"up " + v2},
+		},
+		{
+			name:    "redo",
+			lookups: [][]string{{v1, v2}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Redo(ctx, 1) },
+			want:    []string{"down " + v2, "up " + v2},
+		},
+		{
+			name: "target not found",
+			run: func(ctx context.Context, m *Migrator) ([]Result, error) {
+				return m.UpTo(ctx, cli.MigrationFilterFlag{Value: "create_d", Type: "name"})
+			},
+			wantErr: ErrMigrationNotFound,
+		},
+		{
+			name:    "failed script",
+			lookups: [][]string{{v1}},
+			run:     func(ctx context.Context, m *Migrator) ([]Result, error) { return m.Up(ctx, 0) },
+			fail:    v2,
+			wantErr: errScript,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			expectMigrations(db, tt.lookups, tt.want)
+
+			if tt.fail != "" {
+				db.ExpectExec(regexp.QuoteMeta(testMigrations[position(tt.fail)].Up)).WillReturnError(errScript)
+			}
+
+			m, err := NewWithMigrations(db, testMigrations, Options{Adapter: sqldb.SQLite3})
+			if err != nil {
+				t.Fatalf("NewWithMigrations() error = %v", err)
+			}
+
+			results, err := tt.run(context.Background(), m)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, result := range results {
+				got = append(got, string(result.Direction)+" "+result.Version)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("results = %v, want %v", got, tt.want)
+			}
+
+			if tt.fail != "" {
+				calls := db.Calls()
+				if last := calls[len(calls)-1]; last.Kind != sqltest.ROLLBACK {
+					t.Errorf("last call = %v, want a rollback", last)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	db := sqltest.New(t)
+	db.ExpectExec(`^CREATE TABLE IF NOT EXISTS schema_migrations `)
+	db.ExpectQuery(`^SELECT version, name, applied_at FROM schema_migrations$`).WillReturnRows(
+		[]string{"version", "name", "applied_at"},
+		[]any{"20240101000000000000", "create_a", "2024-01-01 12:00:00"},
+		[]any{"20231231000000000000", "create_z", appliedAt},
+	)
+
+	m, err := NewWithMigrations(db, testMigrations, Options{Adapter: sqldb.SQLite3})
+	if err != nil {
+		t.Fatalf("NewWithMigrations() error = %v", err)
+	}
+
+	got, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	want := []MigrationStatus{
+		{Migration: Migration{Version: "20231231000000000000", Name: "create_z"}, Applied: true, AppliedAt: &appliedAt, Missing: true},
+		{Migration: testMigrations[0], Applied: true, AppliedAt: &appliedAt},
+		{Migration: testMigrations[1]},
+		{Migration: testMigrations[2]},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Status() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Package outbox implements the transactional outbox pattern.
+//
+// Events are written to an outbox table in the same transaction as the business data they describe,
+// so they are recorded if and only if that data is committed. A Relay then reads undelivered events,
+// hands them to a Sink (an HTTP endpoint, a file or a callback) and marks them delivered.
+//
+// Delivery is at-least-once: an event is only marked delivered after its sink accepted it, so a relay
+// crashing in between delivers it again once its lease expires. Consumers should deduplicate events by ID.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+const DEFAULT_TABLE_NAME = "outbox"
+
+var (
+	ErrNoTransaction = errors.New("outbox events must be published inside a transaction")
+
+	eventColumns = "id, topic, payload, attempts, created_at"
+)
+
+type (
+	Outbox struct {
+		db      sqldb.SqlBackend
+		adapter sqldb.SQLAdapter
+		table   string
+
+		now func() time.Time
+	}
+
+	Options struct {
+		// The database engine storing the outbox. Must be one of sqldb.PostgreSQL or sqldb.SQLite3.
+		Adapter sqldb.SQLAdapter
+
+		// Name of the table storing events. Defaults to `outbox`.
+		TableName string
+	}
+
+	Event struct {
+		ID        int64           `json:"id" yaml:"id"`
+		Topic     string          `json:"topic" yaml:"topic"`
+		Payload   json.RawMessage `json:"payload" yaml:"payload"`
+		Attempts  int             `json:"attempts" yaml:"attempts"`
+		CreatedAt time.Time       `json:"created_at" yaml:"created_at"`
+	}
+)
+
+// New - returns the outbox stored in the given database.
+//
+// Usage:
+//
+//	box, err := outbox.New(db, outbox.Options{Adapter: sqldb.PostgreSQL})
+//	err = box.Init(ctx)
+//
+//	err = sqldb.WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+//		// ... insert the order
+//		_, err := box.Publish(ctx, "orders.created", order)
+//		return err
+//	})
+func New(db sqldb.SqlBackend, options Options) (*Outbox, error) {
+	if db == nil {
+		return nil, fmt.Errorf("no database provided")
+	}
+
+	if options.Adapter != sqldb.PostgreSQL && options.Adapter != sqldb.SQLite3 {
+		return nil, fmt.Errorf("unsupported adapter: %q", options.Adapter)
+	}
+
+	table := options.TableName
+	if table == "" {
+		table = DEFAULT_TABLE_NAME
+	}
+
+	if !sqldb.IdentifierValidationPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid outbox table name: %q", table)
+	}
+
+	return &Outbox{db: db, adapter: options.Adapter, table: table, now: time.Now}, nil
+}
+
+// Init - creates the outbox table if it does not exist yet.
+//
+// Timestamps are stored as Unix milliseconds so they compare the same way on every adapter.
+func (o *Outbox) Init(ctx context.Context) error {
+	id, payload := "INTEGER PRIMARY KEY AUTOINCREMENT", "BLOB"
+	index := fmt.Sprintf("%s_pending_idx ON %s", o.table, o.table)
+
+	if schema, table, ok := strings.Cut(o.table, "."); ok {
+		// SQLite qualifies the index with the schema, Postgres creates it in the schema of the table.
+		index = fmt.Sprintf("%s.%s_pending_idx ON %s", schema, table, table)
+	}
+
+	if o.adapter == sqldb.PostgreSQL {
+		id, payload = "BIGSERIAL PRIMARY KEY", "BYTEA"
+		index = fmt.Sprintf("%s_pending_idx ON %s", strings.ReplaceAll(o.table, ".", "_"), o.table)
+	}
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id %s,
+			topic TEXT NOT NULL,
+			payload %s NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			available_at BIGINT NOT NULL,
+			locked_until BIGINT,
+			delivered_at BIGINT,
+			created_at BIGINT NOT NULL
+		)`, o.table, id, payload),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s (delivered_at, available_at)`, index),
+	}
+
+	for _, statement := range statements {
+		if _, err := o.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Publish - records an event with the JSON encoding of the payload.
+//
+// The context must carry a transaction started by sqldb.WithTx, which the event is written in,
+// so that it is only relayed if the transaction commits. Returns ErrNoTransaction otherwise.
+func (o *Outbox) Publish(ctx context.Context, topic string, payload any) (int64, error) {
+	tx, ok := sqldb.TxFromContext(ctx)
+	if !ok {
+		return 0, ErrNoTransaction
+	}
+
+	if topic == "" {
+		return 0, fmt.Errorf("no topic provided")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	now := o.now().UnixMilli()
+	placeholders := helpers.EnumerateArgs(4, func(index, _ int) string { return o.adapter.Placeholder(index) })
+
+	var id int64
+	err = tx.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %s (topic, payload, available_at, created_at) VALUES (%s) RETURNING id`, o.table, placeholders),
+		topic, data, now, now,
+	).Scan(&id)
+
+	return id, err
+}
+
+// Pending - returns the number of events that have not been delivered yet.
+func (o *Outbox) Pending(ctx context.Context) (int64, error) {
+	var count int64
+	err := o.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE delivered_at IS NULL`, o.table)).Scan(&count)
+
+	return count, err
+}
+
+// Purge - deletes the events delivered more than `age` ago, returning how many were deleted.
+func (o *Outbox) Purge(ctx context.Context, age time.Duration) (int64, error) {
+	result, err := o.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE delivered_at IS NOT NULL AND delivered_at < %s`, o.table, o.adapter.Placeholder(1)),
+		o.now().Add(-age).UnixMilli(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Decode - unmarshals the JSON payload of the event into `v`.
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
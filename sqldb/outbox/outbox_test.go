@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+var now = time.UnixMilli(1700000000000)
+
+func newOutbox(t *testing.T, db sqldb.SqlBackend) *Outbox {
+	o, err := New(db, Options{Adapter: sqldb.SQLite3})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	o.now = func() time.Time { return now }
+	return o
+}
+
+func TestOutbox_Publish(t *testing.T) {
+	db := sqltest.New(t)
+	o := newOutbox(t, db)
+
+	if _, err := o.Publish(context.Background(), "orders.created", 1); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("Publish() error = %v, want %v", err, ErrNoTransaction)
+	}
+
+	db.ExpectQuery(`^INSERT INTO outbox \(topic, payload, available_at, created_at\) VALUES \(\?, \?, \?, \?\) RETURNING id$`).
+		WithArgs("orders.created", []byte(`{"id":42}`), now.UnixMilli(), now.UnixMilli()).
+		WillReturnRows([]string{"id"}, []any{int64(1)})
+
+	err := sqldb.WithTx(context.Background(), db, nil, func(ctx context.Context, _ *sql.Tx) error {
+		id, err := o.Publish(ctx, "orders.created", map[string]int{"id": 42})
+		if id != 1 {
+			t.Errorf("Publish() = %v, want 1", id)
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Errorf("Publish() error = %v", err)
+	}
+}
+
+func TestRelay_Deliver(t *testing.T) {
+	db := sqltest.New(t)
+	o := newOutbox(t, db)
+
+	db.ExpectQuery(`^UPDATE outbox SET locked_until = \?`).
+		WithArgs(now.Add(DEFAULT_LEASE).UnixMilli(), now.UnixMilli(), now.UnixMilli()).
+		WillReturnRows(
+			[]string{"id", "topic", "payload", "attempts", "created_at"},
+			[]any{int64(2), "orders.paid", []byte(`{}`), int64(3), now.UnixMilli()},
+			[]any{int64(1), "orders.created", []byte(`{}`), int64(0), now.UnixMilli()},
+		)
+	db.ExpectExec(`^UPDATE outbox SET delivered_at = \?`).WithArgs(now.UnixMilli(), int64(1)).WillReturnResult(0, 1)
+	db.ExpectExec(`^UPDATE outbox SET attempts = attempts \+ 1`).
+		WithArgs("unreachable", now.Add(4*time.Second).UnixMilli(), int64(2)).
+		WillReturnResult(0, 1)
+
+	topics := []string{}
+	relay := NewRelay(o, SinkFunc(func(_ context.Context, event Event) error {
+		topics = append(topics, event.Topic)
+
+		if event.Attempts > 0 {
+			return errors.New("unreachable")
+		}
+
+		return nil
+	}), RelayOptions{Backoff: func(attempt int) time.Duration { return time.Duration(attempt) * time.Second }})
+
+	claimed, delivered, err := relay.Deliver(context.Background())
+	if err != nil || claimed != 2 || delivered != 1 {
+		t.Errorf("Deliver() = %v, %v, %v, want 2, 1, nil", claimed, delivered, err)
+	}
+
+	if want := []string{"orders.created", "orders.paid"}; !reflect.DeepEqual(topics, want) {
+		t.Errorf("Deliver() delivered %v, want %v", topics, want)
+	}
+}
+
+func TestHTTPSink_Deliver(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "rejected", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sink := NewHTTPSink(server.URL, http.Header{"Authorization": {"Bearer token"}})
+
+			err := sink.Deliver(context.Background(), Event{ID: 7, Topic: "orders.created", Payload: []byte(`{"id":42}`)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			want := map[string]string{
+				"Authorization":   "Bearer token",
+				"Content-Type":    "application/json",
+				"Idempotency-Key": "7",
+				"X-Outbox-Topic":  "orders.created",
+			}
+
+			for name, value := range want {
+				if got.Header.Get(name) != value {
+					t.Errorf("Deliver() header %s = %q, want %q", name, got.Header.Get(name), value)
+				}
+			}
+
+			if string(body) != `{"id":42}` {
+				t.Errorf("Deliver() body = %s, want %s", body, `{"id":42}`)
+			}
+		})
+	}
+}
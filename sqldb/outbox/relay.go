@@ -0,0 +1,243 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+const (
+	DEFAULT_BATCH_SIZE    = 100
+	DEFAULT_POLL_INTERVAL = time.Second
+	DEFAULT_LEASE         = time.Minute
+)
+
+type (
+	Relay struct {
+		outbox  *Outbox
+		sink    Sink
+		options RelayOptions
+	}
+
+	RelayOptions struct {
+		// Maximum number of events claimed at once. Defaults to DEFAULT_BATCH_SIZE.
+		BatchSize int
+
+		// How long an idle relay waits before looking for new events. Defaults to DEFAULT_POLL_INTERVAL.
+		PollInterval time.Duration
+
+		// How long claimed events stay hidden from other relays. Events not marked delivered
+		// by then, i.e. because the relay crashed, are claimed again. Defaults to DEFAULT_LEASE.
+		Lease time.Duration
+
+		// Returns how long to wait before delivering an event again after its nth failed attempt.
+		// Defaults to sqldb.ExponentialBackoff.
+		Backoff func(attempt int) time.Duration
+
+		// Called with delivery errors, and with errors reading or updating the outbox (with a zero Event).
+		OnError func(event Event, err error)
+	}
+)
+
+// NewRelay - returns a relay delivering the events of the outbox to the sink.
+//
+// Several relays may run against the same outbox. On Postgres they claim disjoint batches with
+// `FOR UPDATE SKIP LOCKED`; on SQLite, claims are serialized by the database.
+//
+// Usage:
+//
+//	relay := outbox.NewRelay(box, outbox.NewHTTPSink("https://events.example.com", nil), outbox.RelayOptions{})
+//	err := relay.Run(ctx)
+func NewRelay(outbox *Outbox, sink Sink, options RelayOptions) *Relay {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DEFAULT_BATCH_SIZE
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = DEFAULT_POLL_INTERVAL
+	}
+
+	if options.Lease <= 0 {
+		options.Lease = DEFAULT_LEASE
+	}
+
+	if options.Backoff == nil {
+		options.Backoff = sqldb.ExponentialBackoff
+	}
+
+	if options.OnError == nil {
+		options.OnError = func(Event, error) {}
+	}
+
+	return &Relay{outbox: outbox, sink: sink, options: options}
+}
+
+// Run - delivers events until the context is cancelled, then returns nil.
+//
+// A full batch is followed immediately by the next one; otherwise the relay waits for the poll interval.
+func (r *Relay) Run(ctx context.Context) error {
+	if r.sink == nil {
+		return fmt.Errorf("no sink provided")
+	}
+
+	for ctx.Err() == nil {
+		claimed, _, err := r.Deliver(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.options.OnError(Event{}, err)
+		}
+
+		if err == nil && claimed == r.options.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.options.PollInterval):
+		}
+	}
+
+	return nil
+}
+
+// Deliver - claims a batch of due events and hands them to the sink in publication order.
+// Returns the number of events claimed and delivered.
+//
+// Events the sink rejects are retried after the backoff delay, without holding back the rest of the batch.
+func (r *Relay) Deliver(ctx context.Context) (claimed int, delivered int, err error) {
+	events, err := r.claim(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Delivery results are recorded even if the relay is being stopped.
+	recordCtx := context.WithoutCancel(ctx)
+
+	for i, event := range events {
+		if ctx.Err() != nil {
+			// Unattempted events are released so that another relay can pick them up right away.
+			return len(events), delivered, r.release(recordCtx, events[i:])
+		}
+
+		if err := r.sink.Deliver(ctx, event); err != nil {
+			r.options.OnError(event, err)
+
+			if err := r.fail(recordCtx, event, err); err != nil {
+				return len(events), delivered, err
+			}
+
+			continue
+		}
+
+		if err := r.done(recordCtx, event); err != nil {
+			return len(events), delivered, err
+		}
+
+		delivered++
+	}
+
+	return len(events), delivered, nil
+}
+
+func (r *Relay) claim(ctx context.Context) ([]Event, error) {
+	o := r.outbox
+	p := o.adapter.Placeholder
+
+	lock := ""
+	if o.adapter == sqldb.PostgreSQL {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+
+	now := o.now()
+
+	rows, err := o.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE %[1]s SET locked_until = %[2]s
+			WHERE id IN (
+				SELECT id FROM %[1]s
+				WHERE delivered_at IS NULL AND available_at <= %[3]s AND (locked_until IS NULL OR locked_until <= %[4]s)
+				ORDER BY id
+				LIMIT %[5]d%[6]s
+			)
+			RETURNING %[7]s`,
+			o.table, p(1), p(2), p(3), r.options.BatchSize, lock, eventColumns,
+		),
+		now.Add(r.options.Lease).UnixMilli(), now.UnixMilli(), now.UnixMilli(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var (
+			event     Event
+			createdAt int64
+		)
+
+		if err := rows.Scan(&event.ID, &event.Topic, &event.Payload, &event.Attempts, &createdAt); err != nil {
+			return nil, err
+		}
+
+		event.CreatedAt = time.UnixMilli(createdAt)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not guarantee any order.
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	return events, nil
+}
+
+func (r *Relay) done(ctx context.Context, event Event) error {
+	o := r.outbox
+
+	_, err := o.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`UPDATE %s SET delivered_at = %s, locked_until = NULL WHERE id = %s`, o.table, o.adapter.Placeholder(1), o.adapter.Placeholder(2)),
+		o.now().UnixMilli(), event.ID,
+	)
+
+	return err
+}
+
+func (r *Relay) fail(ctx context.Context, event Event, cause error) error {
+	o := r.outbox
+	p := o.adapter.Placeholder
+
+	_, err := o.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE %s SET attempts = attempts + 1, last_error = %s, available_at = %s, locked_until = NULL WHERE id = %s AND delivered_at IS NULL`,
+			o.table, p(1), p(2), p(3),
+		),
+		cause.Error(), o.now().Add(r.options.Backoff(event.Attempts+1)).UnixMilli(), event.ID,
+	)
+
+	return err
+}
+
+func (r *Relay) release(ctx context.Context, events []Event) error {
+	o := r.outbox
+
+	for _, event := range events {
+		_, err := o.db.ExecContext(
+			ctx,
+			fmt.Sprintf(`UPDATE %s SET locked_until = NULL WHERE id = %s AND delivered_at IS NULL`, o.table, o.adapter.Placeholder(1)),
+			event.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/oleoneto/go-toolkit/httpclient"
+)
+
+type (
+	// Sink - delivers events outside of the database. An event is marked delivered once Deliver returns nil.
+	Sink interface {
+		Deliver(ctx context.Context, event Event) error
+	}
+
+	// SinkFunc - adapts a function to the Sink interface.
+	SinkFunc func(ctx context.Context, event Event) error
+
+	// HTTPSink - posts the payload of each event to a URL.
+	//
+	// The event ID is sent in the Idempotency-Key header and its topic in the X-Outbox-Topic header,
+	// so receivers can deduplicate and route events. Responses outside of the 2xx range fail the delivery
+	// with an *httpclient.StatusError.
+	HTTPSink struct {
+		Client *http.Client
+		URL    string
+		Header http.Header
+	}
+
+	// FileSink - appends each event as a line of JSON to a file, syncing it to disk after every write.
+	FileSink struct {
+		mu   sync.Mutex
+		file *os.File
+	}
+)
+
+func (f SinkFunc) Deliver(ctx context.Context, event Event) error { return f(ctx, event) }
+
+// NewHTTPSink - returns a sink posting events to the URL with the shared httpclient client.
+// The given headers are added to every request.
+func NewHTTPSink(url string, header http.Header) *HTTPSink {
+	return &HTTPSink{Client: httpclient.New(), URL: url, Header: header}
+}
+
+func (s *HTTPSink) Deliver(ctx context.Context, event Event) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, httpclient.NewBody(event.Payload))
+	if err != nil {
+		return err
+	}
+
+	for name, values := range s.Header {
+		request.Header[name] = append([]string{}, values...)
+	}
+
+	request.ContentLength = int64(len(event.Payload))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Idempotency-Key", strconv.FormatInt(event.ID, 10))
+	request.Header.Set("X-Outbox-Topic", event.Topic)
+
+	client := s.Client
+	if client == nil {
+		client = httpclient.New()
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	err = httpclient.CheckResponse(response)
+
+	// Draining the body lets the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+
+	return err
+}
+
+// NewFileSink - returns a sink appending events to the file at `path`, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Deliver(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
@@ -0,0 +1,147 @@
+package paginate
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type (
+	// Position of a page, serialized into an opaque token.
+	cursor struct {
+		Kind   string        `json:"k"`
+		Offset int           `json:"o,omitempty"`
+		Values []cursorValue `json:"v,omitempty"`
+	}
+
+	// A key value tagged with its type, so it is bound with the same type it was read with.
+	cursorValue struct {
+		Type  string          `json:"t"`
+		Value json.RawMessage `json:"v,omitempty"`
+	}
+)
+
+const (
+	keysetCursor = "keyset"
+	offsetCursor = "offset"
+)
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token, kind string) (cursor, error) {
+	var c cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if c.Kind != kind || c.Offset < 0 {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+func encodeValue(value any) (cursorValue, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return cursorValue{Type: "null"}, nil
+		}
+
+		v, err := valuer.Value()
+		if err != nil {
+			return cursorValue{}, err
+		}
+
+		return encodeValue(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return cursorValue{Type: "null"}, nil
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		return encodeValue(rv.Elem().Interface())
+	}
+
+	var kind string
+	var v any
+
+	switch {
+	case rv.Type() == reflect.TypeOf(time.Time{}):
+		kind, v = "time", rv.Interface().(time.Time).Format(time.RFC3339Nano)
+	case rv.Kind() == reflect.String:
+		kind, v = "string", rv.String()
+	case rv.Kind() == reflect.Bool:
+		kind, v = "bool", rv.Bool()
+	case rv.CanInt():
+		kind, v = "int", rv.Int()
+	case rv.CanUint():
+		kind, v = "uint", rv.Uint()
+	case rv.CanFloat():
+		kind, v = "float", rv.Float()
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		kind, v = "bytes", rv.Bytes()
+	default:
+		return cursorValue{}, fmt.Errorf("unsupported cursor value type: %T", value)
+	}
+
+	data, err := json.Marshal(v)
+	return cursorValue{Type: kind, Value: data}, err
+}
+
+func decodeValue(c cursorValue) (any, error) {
+	var target any
+
+	switch c.Type {
+	case "null":
+		return nil, nil
+	case "time", "string":
+		target = new(string)
+	case "bool":
+		target = new(bool)
+	case "int":
+		target = new(int64)
+	case "uint":
+		target = new(uint64)
+	case "float":
+		target = new(float64)
+	case "bytes":
+		target = new([]byte)
+	default:
+		return nil, fmt.Errorf("%w: unknown value type %q", ErrInvalidCursor, c.Type)
+	}
+
+	if err := json.Unmarshal(c.Value, target); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	value := reflect.ValueOf(target).Elem().Interface()
+
+	if c.Type == "time" {
+		t, err := time.Parse(time.RFC3339Nano, value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+
+		return t, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,100 @@
+package paginate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oleoneto/go-toolkit/cli/formatters"
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+// Page - a slice of results and the cursor to fetch the next one.
+type Page[T any] struct {
+	Items      []T    `json:"items" yaml:"items"`
+	HasNext    bool   `json:"has_next" yaml:"has_next"`
+	NextCursor string `json:"next_cursor,omitempty" yaml:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty" yaml:"total,omitempty"`
+}
+
+// TableWriter - renders the page items as rows, with one column per struct column.
+// The footer holds the total and the cursor of the next page.
+//
+// This allows pages to be printed through formatters.TableFormatter.
+func (p Page[T]) TableWriter() table.Writer {
+	var zero T
+	columns := sqldb.StructColumns(zero)
+
+	header := make(table.Row, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+
+	if len(columns) == 0 {
+		header = table.Row{"Value"}
+	}
+
+	t := formatters.NewTableWriter(header)
+	t.AppendFooter(table.Row{p.summary()})
+
+	for _, item := range p.Items {
+		if len(columns) == 0 {
+			t.AppendRow(table.Row{item})
+			continue
+		}
+
+		values, _ := sqldb.ColumnValues(item, columns...)
+		t.AppendRow(helpers.Map(values, func(_ int, v any) any { return display(v) }))
+	}
+
+	return t
+}
+
+func (p Page[T]) String() string {
+	lines := make([]string, 0, len(p.Items)+1)
+
+	for _, item := range p.Items {
+		lines = append(lines, fmt.Sprintf("%+v", item))
+	}
+
+	if summary := p.summary(); summary != "" {
+		lines = append(lines, summary)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// summary - describes the total and how to fetch the next page.
+func (p Page[T]) summary() string {
+	parts := []string{}
+
+	if p.Total != nil {
+		parts = append(parts, fmt.Sprintf("total: %d", *p.Total))
+	}
+
+	if p.HasNext {
+		parts = append(parts, "next cursor: "+p.NextCursor)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// display - dereferences pointers so table cells show values rather than addresses.
+func display(value any) any {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return ""
+	}
+
+	return rv.Interface()
+}
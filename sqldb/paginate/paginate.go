@@ -0,0 +1,249 @@
+// Package paginate splits the results of a query.SelectQuery into keyset (cursor) or offset pages.
+//
+// Both strategies return opaque cursor tokens that are handed back to fetch the next page,
+// so callers never need to know which strategy a list endpoint or command uses.
+package paginate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/query"
+	"github.com/spf13/pflag"
+)
+
+const DEFAULT_PAGE_SIZE = 20
+
+// ErrUnsupportedQuery - the base query sets clauses the pagination strategy controls.
+var ErrUnsupportedQuery = errors.New("unsupported base query")
+
+type (
+	Options struct {
+		Adapter sqldb.SQLAdapter
+
+		// Maximum number of items per page. Defaults to DEFAULT_PAGE_SIZE.
+		PageSize int
+
+		// Token returned as Page.NextCursor by the previous call. Empty for the first page.
+		Cursor string
+
+		// Whether to count all the rows matched by the base query.
+		IncludeTotal bool
+	}
+
+	// Column the keyset is sorted by. The combination of all keys must be unique and non-null.
+	Key struct {
+		// Column as written in the SQL statement (i.e. `users.created_at`).
+		Column string
+
+		// Column the value is read from in the scanned struct. Defaults to Column.
+		Field string
+
+		Descending bool
+	}
+)
+
+// BindFlags - registers the `--page-size`, `--cursor` and `--total` flags.
+//
+// Usage:
+//
+//	options := paginate.Options{Adapter: sqldb.PostgreSQL}
+//	options.BindFlags(listCmd.Flags())
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&o.PageSize, "page-size", DEFAULT_PAGE_SIZE, "maximum number of items per page")
+	flags.StringVar(&o.Cursor, "cursor", "", "cursor of the page to fetch, as returned by the previous page")
+	flags.BoolVar(&o.IncludeTotal, "total", false, "count the total number of items")
+}
+
+func (o Options) pageSize() int {
+	if o.PageSize <= 0 {
+		return DEFAULT_PAGE_SIZE
+	}
+
+	return o.PageSize
+}
+
+// Keyset - returns the page of results following the cursor, sorted by the given keys.
+//
+// Each page is fetched with a WHERE clause on the keys of the last item of the previous page,
+// so pages remain stable while rows are inserted or deleted. The base query must not set
+// ORDER BY, LIMIT or OFFSET; these are derived from the keys and the page size.
+//
+// Usage:
+//
+//	base := query.Select("id", "name", "created_at").From("users").Where(query.Eq("active", true))
+//	page, err := Keyset[User](ctx, db, base, []Key{{Column: "created_at", Descending: true}, {Column: "id"}}, options)
+func Keyset[T any](ctx context.Context, db sqldb.SqlBackend, base *query.SelectQuery, keys []Key, options Options) (*Page[T], error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keyset pagination requires at least one key")
+	}
+
+	if base.Sorted() || base.Bounded() {
+		return nil, fmt.Errorf("%w: keyset pagination sets ORDER BY, LIMIT and OFFSET itself", ErrUnsupportedQuery)
+	}
+
+	size := options.pageSize()
+	statement := base.Clone()
+
+	if options.Cursor != "" {
+		c, err := decodeCursor(options.Cursor, keysetCursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(c.Values) != len(keys) {
+			return nil, ErrInvalidCursor
+		}
+
+		values := make([]any, len(c.Values))
+		for i, v := range c.Values {
+			if values[i], err = decodeValue(v); err != nil {
+				return nil, err
+			}
+		}
+
+		statement.Where(after(keys, values))
+	}
+
+	for _, key := range keys {
+		direction := "ASC"
+		if key.Descending {
+			direction = "DESC"
+		}
+
+		statement.OrderBy(key.Column + " " + direction)
+	}
+
+	items, err := fetch[T](ctx, db, statement.Limit(size+1), options.Adapter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: items}
+
+	if len(items) > size {
+		page.Items, page.HasNext = items[:size], true
+
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key.Column
+			if key.Field != "" {
+				fields[i] = key.Field
+			}
+		}
+
+		values, err := sqldb.ColumnValues(page.Items[size-1], fields...)
+		if err != nil {
+			return nil, err
+		}
+
+		c := cursor{Kind: keysetCursor, Values: make([]cursorValue, len(values))}
+		for i, value := range values {
+			if c.Values[i], err = encodeValue(value); err != nil {
+				return nil, err
+			}
+		}
+
+		page.NextCursor = encodeCursor(c)
+	}
+
+	if options.IncludeTotal {
+		if page.Total, err = count(ctx, db, base, options.Adapter); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// Offset - returns the page of results following the cursor using LIMIT and OFFSET.
+//
+// The base query should set an ORDER BY clause on unique columns, otherwise the database
+// is free to return rows in a different order for each page. It must not set LIMIT or OFFSET.
+func Offset[T any](ctx context.Context, db sqldb.SqlBackend, base *query.SelectQuery, options Options) (*Page[T], error) {
+	if base.Bounded() {
+		return nil, fmt.Errorf("%w: offset pagination sets LIMIT and OFFSET itself", ErrUnsupportedQuery)
+	}
+
+	size := options.pageSize()
+	offset := 0
+
+	if options.Cursor != "" {
+		c, err := decodeCursor(options.Cursor, offsetCursor)
+		if err != nil {
+			return nil, err
+		}
+
+		offset = c.Offset
+	}
+
+	items, err := fetch[T](ctx, db, base.Clone().Limit(size+1).Offset(offset), options.Adapter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: items}
+
+	if len(items) > size {
+		page.Items, page.HasNext = items[:size], true
+		page.NextCursor = encodeCursor(cursor{Kind: offsetCursor, Offset: offset + size})
+	}
+
+	if options.IncludeTotal {
+		if page.Total, err = count(ctx, db, base, options.Adapter); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// after - returns the condition matching rows sorted after the given key values.
+//
+// For keys (a, b), this renders: a > ? OR (a = ? AND b > ?)
+func after(keys []Key, values []any) query.Condition {
+	alternatives := make([]query.Condition, len(keys))
+
+	for i, key := range keys {
+		conditions := make([]query.Condition, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, query.Eq(keys[j].Column, values[j]))
+		}
+
+		if key.Descending {
+			conditions = append(conditions, query.Lt(key.Column, values[i]))
+		} else {
+			conditions = append(conditions, query.Gt(key.Column, values[i]))
+		}
+
+		alternatives[i] = query.And(conditions...)
+	}
+
+	return query.Or(alternatives...)
+}
+
+func fetch[T any](ctx context.Context, db sqldb.SqlBackend, statement *query.SelectQuery, adapter sqldb.SQLAdapter) ([]T, error) {
+	sql, args, err := statement.Build(adapter)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqldb.QueryAll[T](ctx, db, sql, args...)
+}
+
+func count(ctx context.Context, db sqldb.SqlBackend, base *query.SelectQuery, adapter sqldb.SQLAdapter) (*int64, error) {
+	sql, args, err := base.Build(adapter)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS paginated", sql), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	return &total, nil
+}
@@ -0,0 +1,152 @@
+package paginate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/query"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func Test_cursorValues(t *testing.T) {
+	id := uuid.MustParse("0b5e2a0c-8a5e-4b8f-9d39-3b7a3f1d2c4e")
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{name: "string", value: "alice", want: "alice"},
+		{name: "int", value: 42, want: int64(42)},
+		{name: "uint", value: uint8(7), want: uint64(7)},
+		{name: "float", value: 1.5, want: 1.5},
+		{name: "bool", value: true, want: true},
+		{name: "time", value: now, want: now},
+		{name: "uuid", value: id, want: id.String()},
+		{name: "pointer", value: helpers.PointerTo("bob"), want: "bob"},
+		{name: "nil pointer", value: (*string)(nil), want: nil},
+		{name: "bytes", value: []byte("raw"), want: []byte("raw")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := encodeValue(tt.value)
+			if err != nil {
+				t.Fatalf("encodeValue() error = %v", err)
+			}
+
+			token := encodeCursor(cursor{Kind: keysetCursor, Values: []cursorValue{encoded}})
+
+			c, err := decodeCursor(token, keysetCursor)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+
+			got, err := decodeValue(c.Values[0])
+			if err != nil {
+				t.Fatalf("decodeValue() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeCursor(t *testing.T) {
+	offset := encodeCursor(cursor{Kind: offsetCursor, Offset: 20})
+
+	if _, err := decodeCursor(offset, keysetCursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("decodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+	}
+
+	if _, err := decodeCursor("not a cursor", offsetCursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("decodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+	}
+
+	if c, err := decodeCursor(offset, offsetCursor); err != nil || c.Offset != 20 {
+		t.Errorf("decodeCursor() = %v, %v, want offset 20", c, err)
+	}
+}
+
+func Test_after(t *testing.T) {
+	keys := []Key{{Column: "created_at", Descending: true}, {Column: "id"}}
+
+	statement, args, err := query.Select().From("users").Where(after(keys, []any{"2024-01-01", 7})).Build(sqldb.PostgreSQL)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "SELECT * FROM users WHERE (created_at < $1 OR (created_at = $2 AND id > $3))"
+	if statement != want {
+		t.Errorf("after() = %v, want %v", statement, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{"2024-01-01", "2024-01-01", 7}) {
+		t.Errorf("after() args = %v", args)
+	}
+}
+
+func TestKeyset_unsupportedQuery(t *testing.T) {
+	keys := []Key{{Column: "id"}}
+
+	tests := []struct {
+		name string
+		base *query.SelectQuery
+	}{
+		{name: "order by", base: query.Select().From("users").OrderBy("name")},
+		{name: "limit", base: query.Select().From("users").Limit(10)},
+		{name: "offset", base: query.Select().From("users").Offset(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+
+			if _, err := Keyset[int](context.Background(), db, tt.base, keys, Options{Adapter: sqldb.SQLite3}); !errors.Is(err, ErrUnsupportedQuery) {
+				t.Errorf("Keyset() error = %v, want %v", err, ErrUnsupportedQuery)
+			}
+
+			if calls := db.Calls(); len(calls) != 0 {
+				t.Errorf("Keyset() ran %v, want no statements", calls)
+			}
+		})
+	}
+}
+
+func TestOffset_unsupportedQuery(t *testing.T) {
+	db := sqltest.New(t)
+	base := query.Select().From("users").OrderBy("id").Limit(10)
+
+	if _, err := Offset[int](context.Background(), db, base, Options{Adapter: sqldb.SQLite3}); !errors.Is(err, ErrUnsupportedQuery) {
+		t.Errorf("Offset() error = %v, want %v", err, ErrUnsupportedQuery)
+	}
+}
+
+func TestPage_summary(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page[int]
+		want string
+	}{
+		{name: "last page", page: Page[int]{Items: []int{1}}, want: ""},
+		{name: "with total", page: Page[int]{Total: helpers.PointerTo(int64(3))}, want: "total: 3"},
+		{name: "with next", page: Page[int]{HasNext: true, NextCursor: "abc", Total: helpers.PointerTo(int64(3))}, want: "total: 3, next cursor: abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.summary(); got != tt.want {
+				t.Errorf("summary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
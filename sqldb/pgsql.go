@@ -2,14 +2,14 @@ package sqldb
 
 import (
 	"database/sql"
-	"fmt"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
+// UsePG - opens a pool for the Postgres database, after checking the DSN with ParseDSN.
 func UsePG(dsn string) (*sql.DB, error) {
-	if dsn == "" {
-		return nil, fmt.Errorf("no database dsn provided")
+	if _, err := ParseDSN(PostgreSQL, dsn); err != nil {
+		return nil, err
 	}
 
 	d, err := sql.Open("pgx", dsn)
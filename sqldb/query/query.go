@@ -0,0 +1,263 @@
+// Package query builds SELECT, INSERT, UPDATE and DELETE statements whose bind parameters
+// match the placeholder style of a sqldb.SQLAdapter.
+//
+// Table names, column names and ORDER BY expressions are written as given and must never
+// come from user input. Values are always passed as bind parameters.
+//
+// Usage:
+//
+//	statement, args, err := query.Select("id", "name").
+//		From("users").
+//		Where(query.Eq("active", true), query.In("role", []string{"admin", "owner"})).
+//		OrderBy("name ASC").
+//		Limit(10).
+//		Build(sqldb.PostgreSQL)
+//
+//	// statement: SELECT id, name FROM users WHERE active = $1 AND role IN ($2, $3) ORDER BY name ASC LIMIT 10
+//	// args:      [true admin owner]
+//
+//	rows, err := db.QueryContext(ctx, statement, args...)
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+// Condition - renders a boolean SQL expression, binding its values to the statement being built.
+type Condition func(w *writer) string
+
+// writer accumulates the arguments of a statement and renders their placeholders.
+type writer struct {
+	adapter sqldb.SQLAdapter
+	args    []any
+	err     error
+}
+
+func newWriter(adapter sqldb.SQLAdapter) (*writer, error) {
+	if adapter != sqldb.PostgreSQL && adapter != sqldb.SQLite3 {
+		return nil, fmt.Errorf("unsupported adapter: %q", adapter)
+	}
+
+	return &writer{adapter: adapter}, nil
+}
+
+// bind - appends the values to the statement arguments and returns their placeholders.
+func (w *writer) bind(values ...any) string {
+	offset := len(w.args)
+	w.args = append(w.args, values...)
+
+	return helpers.EnumerateArgsOffset(len(values), offset, func(index, _ int) string {
+		return w.adapter.Placeholder(index)
+	})
+}
+
+// where - renders the WHERE clause joining all conditions with AND.
+func (w *writer) where(conditions []Condition) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	parts := helpers.Map(conditions, func(_ int, c Condition) string { return c(w) })
+
+	return " WHERE " + strings.Join(parts, " AND ")
+}
+
+func (w *writer) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Eq - column = value
+//
+// As a special case, a nil value renders `column IS NULL`, since `= NULL` never matches.
+func Eq(column string, value any) Condition { return compare(column, "=", value) }
+
+// NotEq - column <> value
+//
+// As a special case, a nil value renders `column IS NOT NULL`.
+func NotEq(column string, value any) Condition { return compare(column, "<>", value) }
+
+// Lt - column < value
+func Lt(column string, value any) Condition { return compare(column, "<", value) }
+
+// Lte - column <= value
+func Lte(column string, value any) Condition { return compare(column, "<=", value) }
+
+// Gt - column > value
+func Gt(column string, value any) Condition { return compare(column, ">", value) }
+
+// Gte - column >= value
+func Gte(column string, value any) Condition { return compare(column, ">=", value) }
+
+// Like - column LIKE pattern
+func Like(column string, pattern string) Condition { return compare(column, "LIKE", pattern) }
+
+// IsNull - column IS NULL
+func IsNull(column string) Condition {
+	return func(w *writer) string { return column + " IS NULL" }
+}
+
+// IsNotNull - column IS NOT NULL
+func IsNotNull(column string) Condition {
+	return func(w *writer) string { return column + " IS NOT NULL" }
+}
+
+// In - column IN (values...)
+//
+// `values` must be a slice or an array. As a special case, an empty list renders an always false expression.
+//
+// Usage:
+//
+//	In("id", []int{1, 2, 3}) // id IN ($1, $2, $3)
+func In(column string, values any) Condition { return membership(column, "IN", "1 = 0", values) }
+
+// NotIn - column NOT IN (values...)
+//
+// As a special case, an empty list renders an always true expression.
+func NotIn(column string, values any) Condition {
+	return membership(column, "NOT IN", "1 = 1", values)
+}
+
+// And - joins the conditions with AND. As a special case, no conditions render an always true expression.
+func And(conditions ...Condition) Condition { return join(" AND ", "1 = 1", conditions) }
+
+// Or - joins the conditions with OR. As a special case, no conditions render an always false expression.
+func Or(conditions ...Condition) Condition { return join(" OR ", "1 = 0", conditions) }
+
+// Not - negates the condition.
+func Not(condition Condition) Condition {
+	return func(w *writer) string { return "NOT (" + condition(w) + ")" }
+}
+
+// Raw - a literal SQL expression whose `?` markers are replaced by bind parameters for `args`.
+//
+// Markers inside quoted strings and identifiers are left as is, and `??` renders a literal `?`,
+// i.e. for the Postgres jsonb operators `?`, `?|` and `?&`.
+//
+// Usage:
+//
+//	Raw("lower(email) = lower(?)", email)
+//	Raw("tags ?? ?", "go") // tags ? $1
+func Raw(expression string, args ...any) Condition {
+	return func(w *writer) string {
+		parts := splitMarkers(expression)
+		if len(parts)-1 != len(args) {
+			w.fail(fmt.Errorf("expression %q expects %d arguments, got %d", expression, len(parts)-1, len(args)))
+			return expression
+		}
+
+		var out strings.Builder
+		for i, part := range parts {
+			out.WriteString(part)
+
+			if i < len(args) {
+				out.WriteString(w.bind(args[i]))
+			}
+		}
+
+		return out.String()
+	}
+}
+
+// splitMarkers - splits the expression around its `?` markers, skipping quoted segments and unescaping `??`.
+func splitMarkers(expression string) []string {
+	parts := []string{}
+
+	var (
+		part  strings.Builder
+		quote byte
+	)
+
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+
+		switch {
+		case quote != 0:
+			// A doubled quote closes the segment and opens it again, so it needs no special case.
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?' && i+1 < len(expression) && expression[i+1] == '?':
+			i++
+		case c == '?':
+			parts = append(parts, part.String())
+			part.Reset()
+			continue
+		}
+
+		part.WriteByte(c)
+	}
+
+	return append(parts, part.String())
+}
+
+func compare(column, operator string, value any) Condition {
+	return func(w *writer) string {
+		if isNil(value) {
+			switch operator {
+			case "=":
+				return column + " IS NULL"
+			case "<>":
+				return column + " IS NOT NULL"
+			}
+
+			w.fail(fmt.Errorf("cannot compare %s with NULL using %s", column, operator))
+		}
+
+		return fmt.Sprintf("%s %s %s", column, operator, w.bind(value))
+	}
+}
+
+// isNil - returns true for nil and for nil pointers, which drivers bind as NULL.
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func membership(column, operator, whenEmpty string, values any) Condition {
+	return func(w *writer) string {
+		rv := reflect.ValueOf(values)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			w.fail(fmt.Errorf("%s expects a slice of values for %s, got %T", operator, column, values))
+			return whenEmpty
+		}
+
+		if rv.Len() == 0 {
+			return whenEmpty
+		}
+
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = rv.Index(i).Interface()
+		}
+
+		return fmt.Sprintf("%s %s (%s)", column, operator, w.bind(list...))
+	}
+}
+
+func join(separator, whenEmpty string, conditions []Condition) Condition {
+	return func(w *writer) string {
+		switch len(conditions) {
+		case 0:
+			return whenEmpty
+		case 1:
+			return conditions[0](w)
+		}
+
+		parts := helpers.Map(conditions, func(_ int, c Condition) string { return c(w) })
+
+		return "(" + strings.Join(parts, separator) + ")"
+	}
+}
@@ -0,0 +1,189 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+type builder interface {
+	Build(sqldb.SQLAdapter) (string, []any, error)
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     builder
+		adapter   sqldb.SQLAdapter
+		statement string
+		args      []any
+		wantErr   bool
+	}{
+		{
+			name:      "select all",
+			query:     Select().From("users"),
+			adapter:   sqldb.PostgreSQL,
+			statement: "SELECT * FROM users",
+		},
+		{
+			name: "select with conditions - postgresql",
+			query: Select("id", "name").
+				From("users").
+				Where(Eq("active", true), In("role", []string{"admin", "owner"})).
+				OrderBy("name ASC").
+				Limit(10).
+				Offset(20),
+			adapter:   sqldb.PostgreSQL,
+			statement: "SELECT id, name FROM users WHERE active = $1 AND role IN ($2, $3) ORDER BY name ASC LIMIT 10 OFFSET 20",
+			args:      []any{true, "admin", "owner"},
+		},
+		{
+			name: "select with conditions - sqlite3",
+			query: Select("id", "name").
+				From("users").
+				Where(Eq("active", true), In("role", []string{"admin", "owner"})).
+				Offset(20),
+			adapter:   sqldb.SQLite3,
+			statement: "SELECT id, name FROM users WHERE active = ? AND role IN (?, ?) LIMIT -1 OFFSET 20",
+			args:      []any{true, "admin", "owner"},
+		},
+		{
+			name: "select with nested conditions",
+			query: Select("id").
+				From("users").
+				Where(Or(Gt("age", 18), And(IsNotNull("guardian_id"), Not(Like("name", "%bot%")))), NotIn("id", []int{})),
+			adapter:   sqldb.PostgreSQL,
+			statement: "SELECT id FROM users WHERE (age > $1 OR (guardian_id IS NOT NULL AND NOT (name LIKE $2))) AND 1 = 1",
+			args:      []any{18, "%bot%"},
+		},
+		{
+			name:      "select with empty in list",
+			query:     Select().From("users").Where(In("id", []int{})),
+			adapter:   sqldb.SQLite3,
+			statement: "SELECT * FROM users WHERE 1 = 0",
+		},
+		{
+			name:      "select with raw condition",
+			query:     Select().From("users").Where(Eq("id", 1), Raw("lower(email) = lower(?)", "A@B.C")),
+			adapter:   sqldb.PostgreSQL,
+			statement: "SELECT * FROM users WHERE id = $1 AND lower(email) = lower($2)",
+			args:      []any{1, "A@B.C"},
+		},
+		{
+			name:      "select with escaped raw markers",
+			query:     Select().From("posts").Where(Raw("tags ?? ? AND meta ??| array['a?'] AND title <> 'why?' AND \"q?\" = ?", "go", 1)),
+			adapter:   sqldb.PostgreSQL,
+			statement: "SELECT * FROM posts WHERE tags ? $1 AND meta ?| array['a?'] AND title <> 'why?' AND \"q?\" = $2",
+			args:      []any{"go", 1},
+		},
+		{
+			name:      "select with quoted raw marker",
+			query:     Select().From("posts").Where(Raw("title = 'it''s ?' AND id = ?", 1)),
+			adapter:   sqldb.SQLite3,
+			statement: "SELECT * FROM posts WHERE title = 'it''s ?' AND id = ?",
+			args:      []any{1},
+		},
+		{
+			name:      "select with null comparisons",
+			query:     Select().From("users").Where(Eq("deleted_at", nil), NotEq("email", (*string)(nil)), Eq("id", 1)),
+			adapter:   sqldb.PostgreSQL,
+			statement: "SELECT * FROM users WHERE deleted_at IS NULL AND email IS NOT NULL AND id = $1",
+			args:      []any{1},
+		},
+		{
+			name:    "select with null ordering",
+			query:   Select().From("users").Where(Gt("age", nil)),
+			adapter: sqldb.PostgreSQL,
+			wantErr: true,
+		},
+		{
+			name:    "select with invalid raw condition",
+			query:   Select().From("users").Where(Raw("id = ?")),
+			adapter: sqldb.PostgreSQL,
+			wantErr: true,
+		},
+		{
+			name:    "select with invalid in list",
+			query:   Select().From("users").Where(In("id", 1)),
+			adapter: sqldb.PostgreSQL,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported adapter",
+			query:   Select().From("users"),
+			adapter: "mysql",
+			wantErr: true,
+		},
+		{
+			name:      "insert multiple rows",
+			query:     Insert("users").Columns("id", "name").Values(1, "alice").Values(2, "bob").Returning("id"),
+			adapter:   sqldb.PostgreSQL,
+			statement: "INSERT INTO users (id, name) VALUES ($1, $2), ($3, $4) RETURNING id",
+			args:      []any{1, "alice", 2, "bob"},
+		},
+		{
+			name:    "insert with mismatched values",
+			query:   Insert("users").Columns("id", "name").Values(1),
+			adapter: sqldb.PostgreSQL,
+			wantErr: true,
+		},
+		{
+			name:      "upsert - do update",
+			query:     Insert("users").Columns("id", "name", "email").Values(1, "alice", "a@b.c").OnConflict("id").DoUpdate(),
+			adapter:   sqldb.SQLite3,
+			statement: "INSERT INTO users (id, name, email) VALUES (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, email = excluded.email",
+			args:      []any{1, "alice", "a@b.c"},
+		},
+		{
+			name:    "upsert - nothing to update",
+			query:   Insert("tags").Columns("name").Values("go").OnConflict("name").DoUpdate(),
+			adapter: sqldb.PostgreSQL,
+			wantErr: true,
+		},
+		{
+			name:      "upsert - do nothing",
+			query:     Insert("users").Columns("id").Values(1).OnConflict().DoNothing(),
+			adapter:   sqldb.PostgreSQL,
+			statement: "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING",
+			args:      []any{1},
+		},
+		{
+			name:      "update",
+			query:     Update("users").Set("name", "alice").Set("active", false).Where(Eq("id", 1)),
+			adapter:   sqldb.PostgreSQL,
+			statement: "UPDATE users SET name = $1, active = $2 WHERE id = $3",
+			args:      []any{"alice", false, 1},
+		},
+		{
+			name:      "delete",
+			query:     Delete("users").Where(Lt("last_seen", "2024-01-01")).Returning("id"),
+			adapter:   sqldb.SQLite3,
+			statement: "DELETE FROM users WHERE last_seen < ? RETURNING id",
+			args:      []any{"2024-01-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, args, err := tt.query.Build(tt.adapter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if statement != tt.statement {
+				t.Errorf("Build() statement = %v, want %v", statement, tt.statement)
+			}
+
+			if len(args) != 0 || len(tt.args) != 0 {
+				if !reflect.DeepEqual(args, tt.args) {
+					t.Errorf("Build() args = %v, want %v", args, tt.args)
+				}
+			}
+		})
+	}
+}
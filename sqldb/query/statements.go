@@ -0,0 +1,341 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb"
+)
+
+type (
+	SelectQuery struct {
+		columns    []string
+		table      string
+		conditions []Condition
+		groupBy    []string
+		orderBy    []string
+		limit      *int
+		offset     *int
+	}
+
+	InsertQuery struct {
+		table     string
+		columns   []string
+		rows      [][]any
+		conflict  *conflict
+		returning []string
+	}
+
+	UpdateQuery struct {
+		table      string
+		columns    []string
+		values     []any
+		conditions []Condition
+		returning  []string
+	}
+
+	DeleteQuery struct {
+		table      string
+		conditions []Condition
+		returning  []string
+	}
+
+	conflict struct {
+		columns   []string
+		update    []string
+		doNothing bool
+	}
+)
+
+// Select - starts a SELECT statement. No columns selects all of them (*).
+func Select(columns ...string) *SelectQuery { return &SelectQuery{columns: columns} }
+
+// Clone - returns a copy of the statement that can be extended without modifying the original.
+func (q *SelectQuery) Clone() *SelectQuery {
+	clone := *q
+	clone.columns = append([]string{}, q.columns...)
+	clone.conditions = append([]Condition{}, q.conditions...)
+	clone.groupBy = append([]string{}, q.groupBy...)
+	clone.orderBy = append([]string{}, q.orderBy...)
+
+	return &clone
+}
+
+func (q *SelectQuery) From(table string) *SelectQuery {
+	q.table = table
+	return q
+}
+
+// Where - adds conditions to the statement. All conditions must hold (AND).
+func (q *SelectQuery) Where(conditions ...Condition) *SelectQuery {
+	q.conditions = append(q.conditions, conditions...)
+	return q
+}
+
+func (q *SelectQuery) GroupBy(columns ...string) *SelectQuery {
+	q.groupBy = append(q.groupBy, columns...)
+	return q
+}
+
+// OrderBy - adds sorting expressions such as `created_at DESC`.
+func (q *SelectQuery) OrderBy(expressions ...string) *SelectQuery {
+	q.orderBy = append(q.orderBy, expressions...)
+	return q
+}
+
+func (q *SelectQuery) Limit(limit int) *SelectQuery {
+	q.limit = &limit
+	return q
+}
+
+func (q *SelectQuery) Offset(offset int) *SelectQuery {
+	q.offset = &offset
+	return q
+}
+
+// Sorted - whether the statement sets ORDER BY.
+func (q *SelectQuery) Sorted() bool { return len(q.orderBy) > 0 }
+
+// Bounded - whether the statement sets LIMIT or OFFSET.
+func (q *SelectQuery) Bounded() bool { return q.limit != nil || q.offset != nil }
+
+// Build - renders the statement and its arguments for the given adapter.
+func (q *SelectQuery) Build(adapter sqldb.SQLAdapter) (string, []any, error) {
+	w, err := newWriter(adapter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if q.table == "" {
+		return "", nil, fmt.Errorf("no table provided")
+	}
+
+	columns := "*"
+	if len(q.columns) > 0 {
+		columns = strings.Join(q.columns, ", ")
+	}
+
+	statement := fmt.Sprintf("SELECT %s FROM %s", columns, q.table) + w.where(q.conditions)
+
+	if len(q.groupBy) > 0 {
+		statement += " GROUP BY " + strings.Join(q.groupBy, ", ")
+	}
+
+	if len(q.orderBy) > 0 {
+		statement += " ORDER BY " + strings.Join(q.orderBy, ", ")
+	}
+
+	if q.limit != nil {
+		statement += fmt.Sprintf(" LIMIT %d", *q.limit)
+	} else if q.offset != nil && adapter == sqldb.SQLite3 {
+		// SQLite only accepts OFFSET as part of a LIMIT clause.
+		statement += " LIMIT -1"
+	}
+
+	if q.offset != nil {
+		statement += fmt.Sprintf(" OFFSET %d", *q.offset)
+	}
+
+	return statement, w.args, w.err
+}
+
+// Insert - starts an INSERT statement.
+//
+// Usage:
+//
+//	Insert("users").Columns("id", "name").Values(1, "alice").Values(2, "bob").Build(sqldb.SQLite3)
+//	// INSERT INTO users (id, name) VALUES (?, ?), (?, ?)
+func Insert(table string) *InsertQuery { return &InsertQuery{table: table} }
+
+func (q *InsertQuery) Columns(columns ...string) *InsertQuery {
+	q.columns = append(q.columns, columns...)
+	return q
+}
+
+// Values - adds a row to the statement. Values must be in the same order as the columns.
+func (q *InsertQuery) Values(values ...any) *InsertQuery {
+	q.rows = append(q.rows, values)
+	return q
+}
+
+// OnConflict - turns the statement into an upsert on the given unique columns.
+// Follow it with DoNothing or DoUpdate.
+func (q *InsertQuery) OnConflict(columns ...string) *InsertQuery {
+	q.conflict = &conflict{columns: columns}
+	return q
+}
+
+// DoNothing - ignores rows that conflict with existing ones.
+func (q *InsertQuery) DoNothing() *InsertQuery {
+	if q.conflict == nil {
+		q.conflict = &conflict{}
+	}
+
+	q.conflict.doNothing = true
+	q.conflict.update = nil
+	return q
+}
+
+// DoUpdate - overwrites the given columns of conflicting rows with the inserted values.
+// No columns updates every inserted column not part of the conflict target, and Build fails if there is none.
+func (q *InsertQuery) DoUpdate(columns ...string) *InsertQuery {
+	if q.conflict == nil {
+		q.conflict = &conflict{}
+	}
+
+	q.conflict.doNothing = false
+	q.conflict.update = columns
+	return q
+}
+
+func (q *InsertQuery) Returning(columns ...string) *InsertQuery {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+// Build - renders the statement and its arguments for the given adapter.
+func (q *InsertQuery) Build(adapter sqldb.SQLAdapter) (string, []any, error) {
+	w, err := newWriter(adapter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if q.table == "" || len(q.columns) == 0 {
+		return "", nil, fmt.Errorf("no table or columns provided")
+	}
+
+	if len(q.rows) == 0 {
+		return "", nil, fmt.Errorf("no values provided")
+	}
+
+	rows := make([]string, len(q.rows))
+	for i, row := range q.rows {
+		if len(row) != len(q.columns) {
+			return "", nil, fmt.Errorf("row %d has %d values, expected %d", i, len(row), len(q.columns))
+		}
+
+		rows[i] = "(" + w.bind(row...) + ")"
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", q.table, strings.Join(q.columns, ", "), strings.Join(rows, ", "))
+
+	if q.conflict != nil {
+		statement += " ON CONFLICT"
+
+		if len(q.conflict.columns) > 0 {
+			statement += " (" + strings.Join(q.conflict.columns, ", ") + ")"
+		}
+
+		if q.conflict.doNothing {
+			statement += " DO NOTHING"
+		} else {
+			if len(q.conflict.columns) == 0 {
+				return "", nil, fmt.Errorf("DO UPDATE requires conflict columns")
+			}
+
+			update := q.conflict.update
+			if len(update) == 0 {
+				update = helpers.Filter(q.columns, func(_ int, column string) bool {
+					return !helpers.Contains(q.conflict.columns, column)
+				})
+			}
+
+			if len(update) == 0 {
+				return "", nil, fmt.Errorf("DO UPDATE has no columns to update, use DO NOTHING instead")
+			}
+
+			sets := helpers.Map(update, func(_ int, column string) string {
+				return fmt.Sprintf("%s = excluded.%s", column, column)
+			})
+
+			statement += " DO UPDATE SET " + strings.Join(sets, ", ")
+		}
+	}
+
+	return statement + returning(q.returning), w.args, w.err
+}
+
+// Update - starts an UPDATE statement.
+//
+// Usage:
+//
+//	Update("users").Set("name", "alice").Where(Eq("id", 1)).Build(sqldb.PostgreSQL)
+//	// UPDATE users SET name = $1 WHERE id = $2
+func Update(table string) *UpdateQuery { return &UpdateQuery{table: table} }
+
+func (q *UpdateQuery) Set(column string, value any) *UpdateQuery {
+	q.columns = append(q.columns, column)
+	q.values = append(q.values, value)
+	return q
+}
+
+// Where - adds conditions to the statement. All conditions must hold (AND).
+func (q *UpdateQuery) Where(conditions ...Condition) *UpdateQuery {
+	q.conditions = append(q.conditions, conditions...)
+	return q
+}
+
+func (q *UpdateQuery) Returning(columns ...string) *UpdateQuery {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+// Build - renders the statement and its arguments for the given adapter.
+func (q *UpdateQuery) Build(adapter sqldb.SQLAdapter) (string, []any, error) {
+	w, err := newWriter(adapter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if q.table == "" || len(q.columns) == 0 {
+		return "", nil, fmt.Errorf("no table or columns provided")
+	}
+
+	sets := make([]string, len(q.columns))
+	for i, column := range q.columns {
+		sets[i] = fmt.Sprintf("%s = %s", column, w.bind(q.values[i]))
+	}
+
+	statement := fmt.Sprintf("UPDATE %s SET %s", q.table, strings.Join(sets, ", ")) + w.where(q.conditions)
+
+	return statement + returning(q.returning), w.args, w.err
+}
+
+// Delete - starts a DELETE statement.
+func Delete(table string) *DeleteQuery { return &DeleteQuery{table: table} }
+
+// Where - adds conditions to the statement. All conditions must hold (AND).
+func (q *DeleteQuery) Where(conditions ...Condition) *DeleteQuery {
+	q.conditions = append(q.conditions, conditions...)
+	return q
+}
+
+func (q *DeleteQuery) Returning(columns ...string) *DeleteQuery {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+// Build - renders the statement and its arguments for the given adapter.
+func (q *DeleteQuery) Build(adapter sqldb.SQLAdapter) (string, []any, error) {
+	w, err := newWriter(adapter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if q.table == "" {
+		return "", nil, fmt.Errorf("no table provided")
+	}
+
+	statement := fmt.Sprintf("DELETE FROM %s", q.table) + w.where(q.conditions)
+
+	return statement + returning(q.returning), w.args, w.err
+}
+
+func returning(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	return " RETURNING " + strings.Join(columns, ", ")
+}
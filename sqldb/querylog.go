@@ -0,0 +1,169 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/rs/zerolog"
+	sqldblogger "github.com/simukti/sqldb-logger"
+)
+
+const REDACTED_ARG = "[REDACTED]"
+
+// Packages skipped when looking up the caller of a slow query.
+var queryCallerPackages = []string{
+	"database/sql",
+	"runtime",
+	"context",
+	"github.com/simukti/sqldb-logger",
+	"github.com/oleoneto/go-toolkit/sqldb",
+}
+
+var queryLogLevels = map[sqldblogger.Level]zerolog.Level{
+	sqldblogger.LevelTrace: zerolog.TraceLevel,
+	sqldblogger.LevelDebug: zerolog.DebugLevel,
+	sqldblogger.LevelInfo:  zerolog.InfoLevel,
+	sqldblogger.LevelError: zerolog.ErrorLevel,
+}
+
+type (
+	// QueryLogger - receives an entry for every call made to the database driver.
+	QueryLogger interface {
+		LogQuery(ctx context.Context, entry QueryLogEntry)
+	}
+
+	QueryLogEntry struct {
+		Level zerolog.Level
+
+		// Driver call, i.e. QueryContext, ExecContext, BeginTx or Connect.
+		Operation string
+
+		Query string
+		Args  []any
+		Error string
+
+		// Time spent in the driver call. Drivers that compute rows lazily, such as SQLite's,
+		// report the time until the first row is ready rather than the time to read every row.
+		Duration time.Duration
+
+		// Whether the call took longer than QueryLogOptions.SlowThreshold.
+		Slow bool
+
+		// Function and location of the code that issued a slow query.
+		Caller         string
+		CallerLocation string
+	}
+
+	QueryLogOptions struct {
+		// Destination of the entries. Defaults to JSON lines on stdout.
+		Logger QueryLogger
+
+		// Minimum level of the entries passed to Logger. Slow queries are logged at zerolog.WarnLevel.
+		Level zerolog.Level
+
+		// Whether bind arguments are replaced with REDACTED_ARG.
+		RedactArgs bool
+
+		// Duration above which queries are reported as slow. Zero disables slow query reporting.
+		SlowThreshold time.Duration
+	}
+
+	zerologQueryLogger struct {
+		logger zerolog.Logger
+	}
+
+	queryLogAdapter struct {
+		options QueryLogOptions
+	}
+)
+
+// NewZerologQueryLogger - returns a QueryLogger that writes entries as structured zerolog events.
+func NewZerologQueryLogger(logger zerolog.Logger) QueryLogger {
+	return zerologQueryLogger{logger: logger}
+}
+
+func (z zerologQueryLogger) LogQuery(_ context.Context, entry QueryLogEntry) {
+	event := z.logger.WithLevel(entry.Level).Dur("duration", entry.Duration)
+
+	if entry.Query != "" {
+		event = event.Str("query", entry.Query)
+	}
+
+	if len(entry.Args) > 0 {
+		event = event.Interface("args", entry.Args)
+	}
+
+	if entry.Error != "" {
+		event = event.Str("error", entry.Error)
+	}
+
+	message := entry.Operation
+
+	if entry.Slow {
+		event = event.Bool("slow", true).Str("caller", entry.Caller).Str("location", entry.CallerLocation)
+		message = "slow " + entry.Operation
+	}
+
+	event.Msg(message)
+}
+
+// WithQueryLog - opens a pool on the driver that reports every driver call to the logger in `options`.
+//
+// Connect and UseSQLite call it when logging is enabled, so it is only needed for drivers opened by other means.
+//
+// Usage:
+//
+//	db := WithQueryLog(dsn, stdlib.GetDefaultDriver(), QueryLogOptions{
+//		Level:         zerolog.InfoLevel,
+//		RedactArgs:    true,
+//		SlowThreshold: 200 * time.Millisecond,
+//	})
+func WithQueryLog(dsn string, drv driver.Driver, options QueryLogOptions) *sql.DB {
+	if options.Logger == nil {
+		options.Logger = NewZerologQueryLogger(zerolog.New(os.Stdout).With().Timestamp().Logger())
+	}
+
+	return sqldblogger.OpenDriver(
+		dsn,
+		drv,
+		queryLogAdapter{options: options},
+		sqldblogger.WithMinimumLevel(sqldblogger.LevelDebug),
+		sqldblogger.WithDurationUnit(sqldblogger.DurationNanosecond),
+	)
+}
+
+// Log - implements sqldblogger.Logger.
+func (a queryLogAdapter) Log(ctx context.Context, level sqldblogger.Level, msg string, data map[string]any) {
+	entry := QueryLogEntry{Level: queryLogLevels[level], Operation: msg}
+
+	entry.Query, _ = data["query"].(string)
+	entry.Error, _ = data["error"].(string)
+
+	if duration, ok := data["duration"].(float64); ok {
+		entry.Duration = time.Duration(duration)
+	}
+
+	if args, ok := data["args"].([]any); ok {
+		entry.Args = args
+
+		if a.options.RedactArgs {
+			entry.Args = helpers.Map(args, func(_ int, _ any) any { return REDACTED_ARG })
+		}
+	}
+
+	if a.options.SlowThreshold > 0 && entry.Query != "" && entry.Duration >= a.options.SlowThreshold {
+		entry.Slow = true
+		entry.Level = max(entry.Level, zerolog.WarnLevel)
+		entry.Caller, entry.CallerLocation = helpers.CallerOutside(queryCallerPackages...)
+	}
+
+	if entry.Level < a.options.Level {
+		return
+	}
+
+	a.options.Logger.LogQuery(ctx, entry)
+}
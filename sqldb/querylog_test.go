@@ -0,0 +1,79 @@
+package sqldb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	sqldblogger "github.com/simukti/sqldb-logger"
+)
+
+type recordingQueryLogger []QueryLogEntry
+
+func (r *recordingQueryLogger) LogQuery(_ context.Context, entry QueryLogEntry) {
+	*r = append(*r, entry)
+}
+
+func Test_queryLogAdapter_Log(t *testing.T) {
+	tests := []struct {
+		name    string
+		options QueryLogOptions
+		level   sqldblogger.Level
+		data    map[string]any
+		want    []QueryLogEntry
+	}{
+		{
+			name:  "query",
+			level: sqldblogger.LevelInfo,
+			data:  map[string]any{"query": "SELECT 1 WHERE ?", "args": []any{true}, "duration": float64(time.Millisecond)},
+			want:  []QueryLogEntry{{Level: zerolog.InfoLevel, Operation: "QueryContext", Query: "SELECT 1 WHERE ?", Args: []any{true}, Duration: time.Millisecond}},
+		},
+		{
+			name:    "redacted arguments",
+			options: QueryLogOptions{RedactArgs: true},
+			level:   sqldblogger.LevelError,
+			data:    map[string]any{"query": "SELECT ?, ?", "args": []any{"secret", 42}, "error": "boom"},
+			want:    []QueryLogEntry{{Level: zerolog.ErrorLevel, Operation: "QueryContext", Query: "SELECT ?, ?", Args: []any{REDACTED_ARG, REDACTED_ARG}, Error: "boom"}},
+		},
+		{
+			name:    "below minimum level",
+			options: QueryLogOptions{Level: zerolog.InfoLevel},
+			level:   sqldblogger.LevelDebug,
+			data:    map[string]any{"duration": float64(time.Millisecond)},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged recordingQueryLogger
+			tt.options.Logger = &logged
+
+			queryLogAdapter{options: tt.options}.Log(context.Background(), tt.level, "QueryContext", tt.data)
+
+			if !reflect.DeepEqual([]QueryLogEntry(logged), tt.want) {
+				t.Errorf("Log() = %+v, want %+v", logged, tt.want)
+			}
+		})
+	}
+}
+
+func Test_queryLogAdapter_Log_slow(t *testing.T) {
+	var logged recordingQueryLogger
+	adapter := queryLogAdapter{options: QueryLogOptions{Logger: &logged, Level: zerolog.WarnLevel, SlowThreshold: time.Second}}
+
+	adapter.Log(context.Background(), sqldblogger.LevelInfo, "ExecContext", map[string]any{"query": "DELETE FROM users", "duration": float64(500 * time.Millisecond)})
+	adapter.Log(context.Background(), sqldblogger.LevelInfo, "ExecContext", map[string]any{"query": "DELETE FROM users", "duration": float64(2 * time.Second)})
+
+	if len(logged) != 1 {
+		t.Fatalf("Log() entries = %d, want 1", len(logged))
+	}
+
+	entry := logged[0]
+	if !entry.Slow || entry.Level != zerolog.WarnLevel || entry.Caller == "" || !strings.Contains(entry.CallerLocation, ".go:") {
+		t.Errorf("Log() = %+v, want slow warning with caller", entry)
+	}
+}
@@ -0,0 +1,317 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/decoder"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+
+	// Column to field mappings, keyed by struct type.
+	columnCache sync.Map
+)
+
+// QueryAll - runs the query and scans every returned row into a T.
+//
+// See ScanRows for how columns are matched to struct fields.
+//
+// Usage:
+//
+//	users, err := QueryAll[User](ctx, db, `SELECT id, name, email FROM users WHERE active = $1`, true)
+func QueryAll[T any](ctx context.Context, db SqlBackend, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ScanRows[T](rows)
+}
+
+// QueryOne - runs the query and scans the first returned row into a T.
+// It returns sql.ErrNoRows if the query yields no rows.
+func QueryOne[T any](ctx context.Context, db SqlBackend, query string, args ...any) (T, error) {
+	var result T
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return result, err
+		}
+
+		return result, sql.ErrNoRows
+	}
+
+	if err := ScanRow(rows, &result); err != nil {
+		return result, err
+	}
+
+	return result, rows.Close()
+}
+
+// ScanRows - scans all rows into a slice of T and closes them.
+//
+// Struct fields are matched to columns by the name in their `db` tag or, if absent, their `json` tag
+// (as resolved by decoder.GetTagValue). Fields tagged with "-" are skipped. Nested structs are matched
+// using dot-separated names (i.e. `author.name`), while embedded structs contribute their fields directly.
+// Use pointer fields for nullable columns: a nested struct pointer is left nil when all of its columns are NULL,
+// as for a row without a match in a LEFT JOIN. Types implementing sql.Scanner, such as uuid.UUID,
+// are scanned as a single column.
+//
+// If T is not a struct, each row must contain exactly one column.
+func ScanRows[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	results := []T{}
+
+	for rows.Next() {
+		var result T
+
+		if err := ScanRow(rows, &result); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// ScanRow - scans the current row into `dest`, which must be a non-nil pointer.
+func ScanRow(rows *sql.Rows, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("scan destination must be a non-nil pointer, got %T", dest)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	rv = rv.Elem()
+
+	if !isNestedStruct(rv.Type()) {
+		if len(columns) != 1 {
+			return fmt.Errorf("cannot scan %d columns into %s", len(columns), rv.Type())
+		}
+
+		return rows.Scan(dest)
+	}
+
+	mapping := columnsOf(rv.Type())
+	targets := make([]any, len(columns))
+	nullable := map[int]reflect.Value{}
+
+	for i, column := range columns {
+		key := strings.ToLower(column)
+
+		path, ok := mapping.fields[key]
+		if !ok {
+			return fmt.Errorf("no field in %s matches column %q", rv.Type(), column)
+		}
+
+		// Columns of a struct pointer are scanned into pointers first, so that the struct is only
+		// allocated if one of them is not NULL, i.e. for the joined row of a LEFT JOIN.
+		if _, ok := mapping.nullable[key]; ok {
+			nullable[i] = reflect.New(reflect.PointerTo(fieldType(rv.Type(), path)))
+			targets[i] = nullable[i].Interface()
+			continue
+		}
+
+		targets[i] = fieldByPath(rv, path).Addr().Interface()
+	}
+
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+
+	for i := range nullable {
+		key := strings.ToLower(columns[i])
+		fieldByPath(rv, mapping.nullable[key]).SetZero()
+	}
+
+	for i, value := range nullable {
+		if !value.Elem().IsNil() {
+			fieldByPath(rv, mapping.fields[strings.ToLower(columns[i])]).Set(value.Elem().Elem())
+		}
+	}
+
+	return nil
+}
+
+// StructColumns - returns the column names mapped to the fields of the struct, in declaration order.
+//
+// See ScanRows for how columns are matched to struct fields.
+func StructColumns(v any) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || !isNestedStruct(t) {
+		return []string{}
+	}
+
+	return append([]string{}, columnsOf(t).names...)
+}
+
+// ColumnValues - returns the values of the struct fields mapped to the given columns.
+func ColumnValues(v any, columns ...string) ([]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || !isNestedStruct(rv.Type()) {
+		return nil, fmt.Errorf("column values require a struct, got %T", v)
+	}
+
+	fields := columnsOf(rv.Type()).fields
+	values := make([]any, len(columns))
+
+	for i, column := range columns {
+		path, ok := fields[strings.ToLower(column)]
+		if !ok {
+			return nil, fmt.Errorf("no field in %s matches column %q", rv.Type(), column)
+		}
+
+		values[i] = valueByPath(rv, path)
+	}
+
+	return values, nil
+}
+
+type structColumns struct {
+	// Column names in field declaration order.
+	names []string
+
+	// Field index path of each column, keyed by the lowercased column name.
+	fields map[string][]int
+
+	// Field index path of the outermost struct pointer holding the column, for columns held by one.
+	nullable map[string][]int
+}
+
+// columnsOf - returns the column mapping of the struct type.
+func columnsOf(t reflect.Type) *structColumns {
+	if cached, ok := columnCache.Load(t); ok {
+		return cached.(*structColumns)
+	}
+
+	columns := &structColumns{fields: map[string][]int{}, nullable: map[string][]int{}}
+	columns.collect(t, "", nil, nil)
+	columnCache.Store(t, columns)
+
+	return columns
+}
+
+func (c *structColumns) collect(t reflect.Type, prefix string, index, nullable []int) {
+	for position := 0; position < t.NumField(); position++ {
+		sf := t.Field(position)
+
+		fieldType := sf.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		name := columnName(sf)
+		if name == "-" {
+			continue
+		}
+
+		path := append(append([]int{}, index...), position)
+
+		if isNestedStruct(fieldType) {
+			scope := prefix + name + "."
+			if sf.Anonymous && !hasColumnTag(sf) {
+				scope = prefix
+			}
+
+			if nullable == nil && sf.Type.Kind() == reflect.Pointer {
+				c.collect(fieldType, scope, path, path)
+			} else {
+				c.collect(fieldType, scope, path, nullable)
+			}
+
+			continue
+		}
+
+		key := strings.ToLower(prefix + name)
+		if _, exists := c.fields[key]; !exists {
+			c.names = append(c.names, prefix+name)
+			c.fields[key] = path
+
+			if nullable != nil {
+				c.nullable[key] = nullable
+			}
+		}
+	}
+}
+
+// columnName - resolves the column name of a struct field from its `db` tag, falling back to its `json` tag.
+func columnName(sf reflect.StructField) string {
+	if _, ok := sf.Tag.Lookup("db"); ok {
+		return decoder.GetTagValue(sf, "db")
+	}
+
+	return decoder.GetJSONTagValue(sf)
+}
+
+func hasColumnTag(sf reflect.StructField) bool {
+	_, db := sf.Tag.Lookup("db")
+	_, json := sf.Tag.Lookup("json")
+	return db || json
+}
+
+// isNestedStruct - returns true for struct types whose fields map to individual columns.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// fieldType - returns the type of the field at the index path.
+func fieldType(t reflect.Type, path []int) reflect.Type {
+	for _, position := range path {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		t = t.Field(position).Type
+	}
+
+	return t
+}
+
+// fieldByPath - returns the field at the index path, allocating nil struct pointers along the way.
+func fieldByPath(v reflect.Value, path []int) reflect.Value {
+	for _, position := range path {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(position)
+	}
+
+	return v
+}
@@ -0,0 +1,180 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func Test_columnsOf(t *testing.T) {
+	type Timestamps struct {
+		CreatedAt time.Time  `json:"created_at"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}
+
+	type Author struct {
+		Id   uuid.UUID `json:"id"`
+		Name string    `json:"name"`
+	}
+
+	type Post struct {
+		Id       uuid.UUID `json:"id"`
+		Title    string    `json:"title" db:"headline"`
+		Body     *string   `json:"body,omitempty"`
+		Author   Author    `json:"author"`
+		Reviewer *Author   `db:"reviewer"`
+		Ignored  string    `db:"-"`
+		Views    int
+		internal string
+		Timestamps
+	}
+
+	wantNames := []string{"id", "headline", "body", "author.id", "author.name", "reviewer.id", "reviewer.name", "Views", "created_at", "deleted_at"}
+	wantFields := map[string][]int{
+		"id":            {0},
+		"headline":      {1},
+		"body":          {2},
+		"author.id":     {3, 0},
+		"author.name":   {3, 1},
+		"reviewer.id":   {4, 0},
+		"reviewer.name": {4, 1},
+		"views":         {6},
+		"created_at":    {8, 0},
+		"deleted_at":    {8, 1},
+	}
+
+	got := columnsOf(reflect.TypeOf(Post{}))
+
+	if !reflect.DeepEqual(got.names, wantNames) {
+		t.Errorf("columnsOf() names = %v, want %v", got.names, wantNames)
+	}
+
+	if !reflect.DeepEqual(got.fields, wantFields) {
+		t.Errorf("columnsOf() fields = %v, want %v", got.fields, wantFields)
+	}
+}
+
+func Test_isNestedStruct(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  bool
+	}{
+		{name: "struct", value: struct{ Name string }{}, want: true},
+		{name: "time", value: time.Time{}, want: false},
+		{name: "uuid", value: uuid.UUID{}, want: false},
+		{name: "scanner", value: sql.NullTime{}, want: false},
+		{name: "string", value: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNestedStruct(reflect.TypeOf(tt.value)); got != tt.want {
+				t.Errorf("isNestedStruct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	type Company struct {
+		Name string `db:"name"`
+	}
+
+	type Author struct {
+		Name    string   `db:"name"`
+		Email   *string  `db:"email"`
+		Company *Company `db:"company"`
+	}
+
+	type Post struct {
+		Id       int64   `db:"id"`
+		Title    string  `db:"title"`
+		Author   Author  `db:"author"`
+		Reviewer *Author `db:"reviewer"`
+	}
+
+	columns := []string{"id", "title", "author.name", "author.email", "reviewer.name", "reviewer.email", "reviewer.company.name"}
+
+	tests := []struct {
+		name    string
+		columns []string
+		rows    [][]any
+		want    []Post
+		wantErr bool
+	}{
+		{
+			name:    "no rows",
+			columns: columns,
+			want:    []Post{},
+		},
+		{
+			name:    "NULL struct pointer",
+			columns: columns,
+			rows:    [][]any{{int64(1), "Hello", "Ada", nil, nil, nil, nil}},
+			want:    []Post{{Id: 1, Title: "Hello", Author: Author{Name: "Ada"}}},
+		},
+		{
+			name:    "partially NULL struct pointer",
+			columns: columns,
+			rows: [][]any{
+				{int64(1), "Hello", "Ada", "ada@example.com", "Grace", nil, nil},
+				{int64(2), "World", "Ada", nil, "Grace", nil, "Navy"},
+			},
+			want: []Post{
+				{Id: 1, Title: "Hello", Author: Author{Name: "Ada", Email: helpers.PointerTo("ada@example.com")}, Reviewer: &Author{Name: "Grace"}},
+				{Id: 2, Title: "World", Author: Author{Name: "Ada"}, Reviewer: &Author{Name: "Grace", Company: &Company{Name: "Navy"}}},
+			},
+		},
+		{
+			name:    "NULL into a non-pointer field",
+			columns: columns,
+			rows:    [][]any{{int64(1), nil, "Ada", nil, nil, nil, nil}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown column",
+			columns: []string{"id", "slug"},
+			rows:    [][]any{{int64(1), "hello"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sqltest.New(t)
+			db.ExpectQuery(`^SELECT`).WillReturnRows(tt.columns, tt.rows...)
+
+			got, err := QueryAll[Post](context.Background(), db, `SELECT * FROM posts`)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QueryAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryAll() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	db := sqltest.New(t)
+	db.ExpectQuery(`^SELECT count`).WillReturnRows([]string{"count"}, []any{int64(42)})
+	db.ExpectQuery(`^SELECT name`).WillReturnRows([]string{"name"})
+
+	count, err := QueryOne[int64](context.Background(), db, `SELECT count(*) FROM posts`)
+	if err != nil || count != 42 {
+		t.Errorf("QueryOne() = %v, %v, want 42", count, err)
+	}
+
+	if _, err := QueryOne[string](context.Background(), db, `SELECT name FROM posts`); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("QueryOne() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
@@ -1,45 +1,292 @@
 package sqldb
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
+)
+
+var (
+	ErrPragmaRejected = errors.New("pragma rejected")
+
+	// Values SQLite reports for keyword settings, keyed by pragma name.
+	pragmaKeywords = map[string]map[string]string{
+		"synchronous":   {"off": "0", "normal": "1", "full": "2", "extra": "3"},
+		"temp_store":    {"default": "0", "file": "1", "memory": "2"},
+		"auto_vacuum":   {"none": "0", "full": "1", "incremental": "2"},
+		"secure_delete": {"off": "0", "on": "1", "fast": "2"},
+	}
 
-	"github.com/rs/zerolog"
-	sqldblogger "github.com/simukti/sqldb-logger"
-	"github.com/simukti/sqldb-logger/logadapter/zerologadapter"
+	// Values SQLite reports for boolean settings.
+	pragmaBooleans = map[string]string{"on": "1", "true": "1", "yes": "1", "off": "0", "false": "0", "no": "0"}
 )
 
+// UseSQLite - opens a pool for the SQLite database and applies the pragmas in `options`
+// to every connection it creates.
+//
+// Each pragma that sets a value is read back after being applied, and connections fail
+// with ErrPragmaRejected if SQLite reports a different value (i.e. WAL on an in-memory database).
+// Pragmas that report nothing, such as case_sensitive_like, are not checked.
+// The first connection is opened eagerly, so such errors are returned here.
+//
+// Usage:
+//
+//	db, err := UseSQLite("app.db", SQLite3Options{
+//		JournalMode: "WAL",
+//		BusyTimeout: 5 * time.Second,
+//		ForeignKeys: helpers.PointerTo(true),
+//		Synchronous: "NORMAL",
+//	})
 func UseSQLite(dbname string, options SQLite3Options) (*sql.DB, error) {
 	if dbname == "" {
 		return nil, fmt.Errorf("no database name provided")
 	}
 
-	d, err := sql.Open("sqlite3", fmt.Sprintf("%s?%s", dbname, strings.Join(options.Flags, "&")))
+	parsed, err := ParseDSN(SQLite3, dbname)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(options.Pragmas) >= 1 {
-		pragmaStmts := strings.Join(options.Pragmas, "; ")
-		d.Exec(pragmaStmts)
+	for _, flag := range options.Flags {
+		params, err := url.ParseQuery(flag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sqlite3 flag %q: %w", flag, err)
+		}
+
+		for name, values := range params {
+			parsed.Params[name] = values
+		}
 	}
 
-	if options.EnableLogging {
-		loggerAdapter := zerologadapter.New(zerolog.New(os.Stdout))
-		d = sqldblogger.OpenDriver(dbname, d.Driver(), loggerAdapter)
+	if err := parsed.Validate(); err != nil {
+		return nil, err
+	}
+
+	dsn := parsed.String()
 
+	// The registered driver is only used to reach its driver.Driver; connections are opened below.
+	registered, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	drv := &sqliteDriver{Driver: registered.Driver(), pragmas: options.pragmas()}
+	registered.Close()
+
+	var d *sql.DB
+
+	queryLog := options.QueryLog
+	if queryLog == nil && options.EnableLogging {
+		queryLog = &QueryLogOptions{}
+	}
+
+	if queryLog != nil {
+		d = WithQueryLog(dsn, drv, *queryLog)
 		if d == nil {
 			return d, fmt.Errorf("database logger failed")
 		}
+	} else {
+		d = sql.OpenDB(&sqliteConnector{dsn: dsn, driver: drv})
+	}
+
+	if err := d.Ping(); err != nil {
+		d.Close()
+		return nil, err
 	}
 
 	return d, nil
 }
 
 type SQLite3Options struct {
-	Flags         []string
-	Pragmas       []string
+	// Query parameters added to the DSN, i.e. `_txlock=immediate` or `cache=shared`.
+	// They replace parameters of the same name given in the database name.
+	Flags []string
+
+	// Additional pragmas applied to every connection, i.e. `cache_size = -20000` or `PRAGMA temp_store = MEMORY`.
+	Pragmas []string
+
+	// Logs every query to stdout. Shorthand for an empty QueryLog.
 	EnableLogging bool
+
+	// Query logging settings. Nil disables query logging unless EnableLogging is set.
+	QueryLog *QueryLogOptions
+
+	// Journal mode, i.e. WAL. Empty keeps SQLite's default.
+	JournalMode string
+
+	// How long a connection waits on a locked database before failing with SQLITE_BUSY. Zero keeps SQLite's default.
+	BusyTimeout time.Duration
+
+	// Whether foreign key constraints are enforced. Nil keeps SQLite's default.
+	ForeignKeys *bool
+
+	// Synchronous level: OFF, NORMAL, FULL or EXTRA. Empty keeps SQLite's default.
+	Synchronous string
+}
+
+type (
+	pragma struct {
+		name  string
+		value string
+	}
+
+	// sqliteDriver - wraps the registered driver to apply pragmas to each new connection.
+	sqliteDriver struct {
+		driver.Driver
+		pragmas []pragma
+	}
+
+	sqliteConnector struct {
+		dsn    string
+		driver *sqliteDriver
+	}
+)
+
+// pragmas - returns the pragmas to apply, with the busy timeout first so the others wait on locks.
+func (o SQLite3Options) pragmas() []pragma {
+	pragmas := []pragma{}
+
+	if o.BusyTimeout > 0 {
+		pragmas = append(pragmas, pragma{"busy_timeout", strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10)})
+	}
+
+	if o.JournalMode != "" {
+		pragmas = append(pragmas, pragma{"journal_mode", o.JournalMode})
+	}
+
+	if o.Synchronous != "" {
+		pragmas = append(pragmas, pragma{"synchronous", o.Synchronous})
+	}
+
+	if o.ForeignKeys != nil {
+		value := "OFF"
+		if *o.ForeignKeys {
+			value = "ON"
+		}
+
+		pragmas = append(pragmas, pragma{"foreign_keys", value})
+	}
+
+	for _, statement := range o.Pragmas {
+		statement = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+		if len(statement) >= 6 && strings.EqualFold(statement[:6], "PRAGMA") {
+			statement = strings.TrimSpace(statement[6:])
+		}
+
+		name, value, _ := strings.Cut(statement, "=")
+		pragmas = append(pragmas, pragma{strings.TrimSpace(name), strings.TrimSpace(value)})
+	}
+
+	return pragmas
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	return d.open(context.Background(), dsn)
+}
+
+func (d *sqliteDriver) open(ctx context.Context, dsn string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range d.pragmas {
+		if err := p.apply(ctx, conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.open(ctx, c.dsn)
+}
+
+func (c *sqliteConnector) Driver() driver.Driver { return c.driver }
+
+// apply - sets the pragma on the connection and, if it sets a value that can be read, checks SQLite reports it back.
+func (p pragma) apply(ctx context.Context, conn driver.Conn) error {
+	execer, canExec := conn.(driver.ExecerContext)
+	queryer, canQuery := conn.(driver.QueryerContext)
+
+	if !canExec || !canQuery {
+		return fmt.Errorf("sqlite3 driver connections cannot run pragmas")
+	}
+
+	statement := "PRAGMA " + p.name
+	if p.value != "" {
+		statement += " = " + p.value
+	}
+
+	if _, err := execer.ExecContext(ctx, statement, nil); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrPragmaRejected, statement, err)
+	}
+
+	if p.value == "" {
+		return nil
+	}
+
+	rows, err := queryer.QueryContext(ctx, "PRAGMA "+p.name, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrPragmaRejected, statement, err)
+	}
+	defer rows.Close()
+
+	values := make([]driver.Value, len(rows.Columns()))
+	if len(values) == 0 {
+		// Write-only pragmas (i.e. case_sensitive_like) and unknown ones report nothing to check.
+		return nil
+	}
+
+	if err := rows.Next(values); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrPragmaRejected, statement, err)
+	}
+
+	got := fmt.Sprint(values[0])
+	if b, ok := values[0].([]byte); ok {
+		got = string(b)
+	}
+
+	if !pragmaMatches(p.name, p.value, got) {
+		return fmt.Errorf("%w: %s: sqlite3 reports %q", ErrPragmaRejected, statement, got)
+	}
+
+	return nil
+}
+
+// pragmaMatches - returns true if the value SQLite reports for the pragma is the one that was set.
+//
+// Keyword values of pragmas missing from pragmaKeywords are reported as numbers that cannot be
+// mapped back to the keyword, so any number is accepted for them.
+func pragmaMatches(name, want, got string) bool {
+	want = strings.ToLower(strings.Trim(want, `'"`))
+	got = strings.ToLower(got)
+
+	if want == got {
+		return true
+	}
+
+	if keyword, ok := pragmaKeywords[strings.ToLower(name)][want]; ok {
+		return keyword == got
+	}
+
+	if boolean, ok := pragmaBooleans[want]; ok {
+		return boolean == got
+	}
+
+	_, wantNumber := strconv.ParseInt(want, 10, 64)
+	_, gotNumber := strconv.ParseInt(got, 10, 64)
+
+	return wantNumber != nil && gotNumber == nil
 }
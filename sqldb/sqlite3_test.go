@@ -0,0 +1,157 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/go-toolkit/helpers"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func TestSQLite3Options_pragmas(t *testing.T) {
+	options := SQLite3Options{
+		JournalMode: "WAL",
+		BusyTimeout: 2500 * time.Millisecond,
+		ForeignKeys: helpers.PointerTo(false),
+		Synchronous: "NORMAL",
+		Pragmas:     []string{"PRAGMA temp_store = MEMORY;", "cache_size=-2000", "optimize"},
+	}
+
+	want := []pragma{
+		{"busy_timeout", "2500"},
+		{"journal_mode", "WAL"},
+		{"synchronous", "NORMAL"},
+		{"foreign_keys", "OFF"},
+		{"temp_store", "MEMORY"},
+		{"cache_size", "-2000"},
+		{"optimize", ""},
+	}
+
+	if got := options.pragmas(); !reflect.DeepEqual(got, want) {
+		t.Errorf("pragmas() = %v, want %v", got, want)
+	}
+}
+
+func Test_pragmaMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		got   string
+		match bool
+	}{
+		{name: "journal_mode", want: "WAL", got: "wal", match: true},
+		{name: "journal_mode", want: "WAL", got: "memory", match: false},
+		{name: "synchronous", want: "normal", got: "1", match: true},
+		{name: "foreign_keys", want: "ON", got: "1", match: true},
+		{name: "foreign_keys", want: "ON", got: "0", match: false},
+		{name: "temp_store", want: "MEMORY", got: "2", match: true},
+		{name: "cache_size", want: "-2000", got: "-2000", match: true},
+		{name: "encoding", want: "'UTF-8'", got: "UTF-8", match: true},
+		{name: "secure_delete", want: "FAST", got: "2", match: true},
+		{name: "secure_delete", want: "FAST", got: "1", match: false},
+		{name: "locking_mode", want: "EXCLUSIVE", got: "exclusive", match: true},
+		{name: "unknown_keyword", want: "ANY", got: "3", match: true},
+		{name: "cache_size", want: "-2000", got: "-1000", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.want, func(t *testing.T) {
+			if got := pragmaMatches(tt.name, tt.want, tt.got); got != tt.match {
+				t.Errorf("pragmaMatches() = %v, want %v", got, tt.match)
+			}
+		})
+	}
+}
+
+func Test_sqliteConnector(t *testing.T) {
+	errExec := errors.New("database is locked")
+
+	tests := []struct {
+		name    string
+		pragmas []pragma
+		expect  func(db *sqltest.Backend)
+		wantErr error
+	}{
+		{
+			name:    "applies and reads back every pragma",
+			pragmas: []pragma{{"busy_timeout", "5000"}, {"journal_mode", "WAL"}, {"foreign_keys", "ON"}, {"optimize", ""}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^PRAGMA busy_timeout = 5000$`)
+				db.ExpectQuery(`^PRAGMA busy_timeout$`).WillReturnRows([]string{"timeout"}, []any{int64(5000)})
+				db.ExpectExec(`^PRAGMA journal_mode = WAL$`)
+				db.ExpectQuery(`^PRAGMA journal_mode$`).WillReturnRows([]string{"journal_mode"}, []any{[]byte("wal")})
+				db.ExpectExec(`^PRAGMA foreign_keys = ON$`)
+				db.ExpectQuery(`^PRAGMA foreign_keys$`).WillReturnRows([]string{"foreign_keys"}, []any{int64(1)})
+				db.ExpectExec(`^PRAGMA optimize$`)
+			},
+		},
+		{
+			name:    "value not reported back",
+			pragmas: []pragma{{"journal_mode", "WAL"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^PRAGMA journal_mode = WAL$`)
+				db.ExpectQuery(`^PRAGMA journal_mode$`).WillReturnRows([]string{"journal_mode"}, []any{"memory"})
+			},
+			wantErr: ErrPragmaRejected,
+		},
+		{
+			name:    "write-only pragma",
+			pragmas: []pragma{{"case_sensitive_like", "ON"}, {"foreign_keys", "ON"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^PRAGMA case_sensitive_like = ON$`)
+				db.ExpectQuery(`^PRAGMA case_sensitive_like$`).WillReturnRows(nil)
+				db.ExpectExec(`^PRAGMA foreign_keys = ON$`)
+				db.ExpectQuery(`^PRAGMA foreign_keys$`).WillReturnRows([]string{"foreign_keys"}, []any{int64(1)})
+			},
+		},
+		{
+			name:    "pragma without rows",
+			pragmas: []pragma{{"case_sensitive_like", "ON"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^PRAGMA case_sensitive_like = ON$`)
+				db.ExpectQuery(`^PRAGMA case_sensitive_like$`).WillReturnRows([]string{"case_sensitive_like"})
+			},
+		},
+		{
+			name:    "failed statement",
+			pragmas: []pragma{{"busy_timeout", "5000"}, {"journal_mode", "WAL"}},
+			expect: func(db *sqltest.Backend) {
+				db.ExpectExec(`^PRAGMA busy_timeout = 5000$`).WillReturnError(errExec)
+			},
+			wantErr: ErrPragmaRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := sqltest.New(t)
+			tt.expect(backend)
+
+			db := sql.OpenDB(&sqliteConnector{dsn: "app.db", driver: &sqliteDriver{Driver: backend.Driver(), pragmas: tt.pragmas}})
+			defer db.Close()
+
+			if err := db.PingContext(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("PingContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_sqliteDriver_Open(t *testing.T) {
+	backend := sqltest.New(t)
+	backend.ExpectExec(`^PRAGMA synchronous = NORMAL$`)
+	backend.ExpectQuery(`^PRAGMA synchronous$`).WillReturnRows([]string{"synchronous"}, []any{int64(1)})
+
+	drv := &sqliteDriver{Driver: backend.Driver(), pragmas: []pragma{{"synchronous", "NORMAL"}}}
+
+	conn, err := drv.Open("app.db")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	conn.Close()
+}
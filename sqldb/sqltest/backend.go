@@ -0,0 +1,227 @@
+// Package sqltest provides an in-memory sqldb.SqlBackend for unit tests.
+//
+// The backend records every statement it receives and answers them from scripted expectations,
+// so code that takes a sqldb.SqlBackend can be tested without a database file or server.
+//
+// Usage:
+//
+//	db := sqltest.New(t)
+//	db.ExpectQuery(`SELECT id, name FROM users WHERE id = \?`).WithArgs(1).WillReturnRows([]string{"id", "name"}, []any{1, "John"})
+//
+//	user, err := sqldb.QueryOne[User](ctx, db, `SELECT id, name FROM users WHERE id = ?`, 1)
+package sqltest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type CallKind string
+
+const (
+	EXEC     CallKind = "exec"
+	QUERY    CallKind = "query"
+	BEGIN    CallKind = "begin"
+	COMMIT   CallKind = "commit"
+	ROLLBACK CallKind = "rollback"
+)
+
+// AnyArg - matches any argument passed in its position. See Expectation.WithArgs.
+var AnyArg any = anyArg{}
+
+type anyArg struct{}
+
+type (
+	// Backend - a fake sqldb.SqlBackend. Statements run inside transactions are recorded and matched as well.
+	Backend struct {
+		*sql.DB
+
+		mu           sync.Mutex
+		expectations []*Expectation
+		calls        []Call
+	}
+
+	// Call - a statement received by the backend. QueryRowContext calls are recorded as QUERY.
+	Call struct {
+		Kind  CallKind
+		Query string
+		Args  []any
+	}
+
+	// Expectation - a scripted response to the statements matching its kind, pattern and arguments.
+	Expectation struct {
+		kind    CallKind
+		pattern *regexp.Regexp
+		args    []any
+
+		columns      []string
+		rows         [][]any
+		lastInsertId int64
+		rowsAffected int64
+		err          error
+
+		times int
+		calls int
+	}
+)
+
+// New - returns an empty backend that is closed, and checked for unmet expectations, when the test ends.
+func New(t testing.TB) *Backend {
+	t.Helper()
+
+	b := NewBackend()
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		if err := b.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+
+		b.Close()
+	})
+
+	return b
+}
+
+// NewBackend - returns an empty backend. Prefer New, unless the backend outlives a single test.
+func NewBackend() *Backend {
+	b := &Backend{}
+	b.DB = sql.OpenDB(&connector{backend: b})
+	return b
+}
+
+// ExpectExec - scripts the response to the next ExecContext call whose query matches the regular expression.
+// Whitespace in queries is collapsed to single spaces before matching.
+func (b *Backend) ExpectExec(pattern string) *Expectation {
+	return b.expect(EXEC, pattern)
+}
+
+// ExpectQuery - scripts the response to the next QueryContext or QueryRowContext call whose query
+// matches the regular expression. Whitespace in queries is collapsed to single spaces before matching.
+func (b *Backend) ExpectQuery(pattern string) *Expectation {
+	return b.expect(QUERY, pattern)
+}
+
+func (b *Backend) expect(kind CallKind, pattern string) *Expectation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := &Expectation{kind: kind, pattern: regexp.MustCompile(pattern), times: 1}
+	b.expectations = append(b.expectations, e)
+
+	return e
+}
+
+// Calls - returns the statements received so far, in order.
+func (b *Backend) Calls() []Call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Call{}, b.calls...)
+}
+
+// ExpectationsWereMet - returns an error describing the expectations that were not matched as many times as required.
+func (b *Backend) ExpectationsWereMet() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending := []string{}
+	for _, e := range b.expectations {
+		if e.calls < e.times {
+			pending = append(pending, fmt.Sprintf("%s %q (matched %d of %d times)", e.kind, e.pattern, e.calls, e.times))
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("unmet expectations:\n\t%s", strings.Join(pending, "\n\t"))
+	}
+
+	return nil
+}
+
+// WithArgs - restricts the expectation to calls with exactly these arguments. Use AnyArg to match any value.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args = append([]any{}, args...)
+	return e
+}
+
+// WillReturnRows - makes a query return the rows, each holding one value per column.
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]any) *Expectation {
+	e.columns, e.rows = columns, rows
+	return e
+}
+
+// WillReturnResult - makes a statement report the given last insert id and number of affected rows.
+func (e *Expectation) WillReturnResult(lastInsertId, rowsAffected int64) *Expectation {
+	e.lastInsertId, e.rowsAffected = lastInsertId, rowsAffected
+	return e
+}
+
+// WillReturnError - makes the call fail with the error.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Times - sets how many calls the expectation answers. Defaults to 1.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) matches(kind CallKind, query string, args []any) bool {
+	if e.kind != kind || e.calls >= e.times || !e.pattern.MatchString(query) {
+		return false
+	}
+
+	if e.args == nil {
+		return true
+	}
+
+	if len(e.args) != len(args) {
+		return false
+	}
+
+	for i, arg := range e.args {
+		if arg != AnyArg && !reflect.DeepEqual(arg, args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handle - records the call and returns the first pending expectation that matches it.
+func (b *Backend) handle(kind CallKind, query string, named []driver.NamedValue) (*Expectation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	query = strings.Join(strings.Fields(query), " ")
+
+	args := make([]any, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+
+	b.calls = append(b.calls, Call{Kind: kind, Query: query, Args: args})
+
+	if kind == BEGIN || kind == COMMIT || kind == ROLLBACK {
+		return nil, nil
+	}
+
+	for _, e := range b.expectations {
+		if e.matches(kind, query, args) {
+			e.calls++
+			return e, e.err
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %s call: %q with args %v", kind, query, args)
+}
@@ -0,0 +1,94 @@
+package sqltest_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oleoneto/go-toolkit/sqldb"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+var _ sqldb.SqlBackend = (*sqltest.Backend)(nil)
+
+type user struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestBackend_query(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.New(t)
+
+	db.ExpectQuery(`^SELECT id, name FROM users WHERE active = \?$`).
+		WithArgs(true).
+		WillReturnRows([]string{"id", "name"}, []any{int64(1), "John"}, []any{int64(2), "Jane"})
+
+	db.ExpectQuery(`FROM users WHERE id = \?`).WithArgs(sqltest.AnyArg)
+
+	users, err := sqldb.QueryAll[user](ctx, db, "SELECT id, name\n\tFROM users WHERE active = ?", true)
+	if err != nil {
+		t.Fatalf("QueryAll() error = %v", err)
+	}
+
+	if want := []user{{Id: 1, Name: "John"}, {Id: 2, Name: "Jane"}}; !reflect.DeepEqual(users, want) {
+		t.Errorf("QueryAll() = %v, want %v", users, want)
+	}
+
+	if _, err := sqldb.QueryOne[user](ctx, db, `SELECT id, name FROM users WHERE id = ?`, 3); err == nil {
+		t.Errorf("QueryOne() error = nil, want sql.ErrNoRows")
+	}
+
+	want := []sqltest.Call{
+		{Kind: sqltest.QUERY, Query: "SELECT id, name FROM users WHERE active = ?", Args: []any{true}},
+		{Kind: sqltest.QUERY, Query: "SELECT id, name FROM users WHERE id = ?", Args: []any{3}},
+	}
+
+	if got := db.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %v, want %v", got, want)
+	}
+}
+
+func TestBackend_exec(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.New(t)
+	failure := errors.New("constraint failed")
+
+	db.ExpectExec(`INSERT INTO users`).WithArgs("John").WillReturnResult(7, 1)
+	db.ExpectExec(`INSERT INTO users`).WillReturnError(failure)
+
+	result, err := db.ExecContext(ctx, `INSERT INTO users (name) VALUES (?)`, "John")
+	if err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	if id, _ := result.LastInsertId(); id != 7 {
+		t.Errorf("LastInsertId() = %v, want %v", id, 7)
+	}
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO users (name) VALUES (?)`, "Jane"); !errors.Is(err, failure) {
+		t.Errorf("ExecContext() error = %v, want %v", err, failure)
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM users`); err == nil || !strings.Contains(err.Error(), "unexpected exec call") {
+		t.Errorf("ExecContext() error = %v, want unexpected call", err)
+	}
+}
+
+func TestBackend_ExpectationsWereMet(t *testing.T) {
+	db := sqltest.NewBackend()
+	defer db.Close()
+
+	db.ExpectExec(`UPDATE users`).Times(2)
+
+	if _, err := db.ExecContext(context.Background(), `UPDATE users SET active = false`); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	err := db.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "matched 1 of 2 times") {
+		t.Errorf("ExpectationsWereMet() error = %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package sqltest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errPrepareUnsupported = errors.New("sqltest does not support prepared statements")
+
+type (
+	connector struct{ backend *Backend }
+
+	conn struct{ backend *Backend }
+
+	tx struct{ backend *Backend }
+
+	result struct{ lastInsertId, rowsAffected int64 }
+
+	rows struct {
+		columns  []string
+		values   [][]any
+		position int
+	}
+)
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{backend: c.backend}, nil
+}
+
+func (c *connector) Driver() driver.Driver { return c }
+
+// Open - satisfies driver.Driver. Connections are only created through Connect.
+func (c *connector) Open(string) (driver.Conn, error) { return &conn{backend: c.backend}, nil }
+
+func (c *conn) Prepare(string) (driver.Stmt, error) { return nil, errPrepareUnsupported }
+func (c *conn) Close() error                        { return nil }
+func (c *conn) Begin() (driver.Tx, error)           { return c.BeginTx(context.Background(), driver.TxOptions{}) }
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	if _, err := c.backend.handle(BEGIN, "BEGIN", nil); err != nil {
+		return nil, err
+	}
+
+	return &tx{backend: c.backend}, nil
+}
+
+// CheckNamedValue - accepts every argument as is, so expectations compare the values given by the caller.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, err := c.backend.handle(EXEC, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return result{lastInsertId: e.lastInsertId, rowsAffected: e.rowsAffected}, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := c.backend.handle(QUERY, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{columns: e.columns, values: e.rows}, nil
+}
+
+func (t *tx) Commit() error {
+	_, err := t.backend.handle(COMMIT, "COMMIT", nil)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, err := t.backend.handle(ROLLBACK, "ROLLBACK", nil)
+	return err
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertId, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.position >= len(r.values) {
+		return io.EOF
+	}
+
+	row := r.values[r.position]
+	if len(row) != len(dest) {
+		return fmt.Errorf("row %d has %d values, want %d", r.position, len(row), len(dest))
+	}
+
+	for i, value := range row {
+		dest[i] = value
+	}
+
+	r.position++
+	return nil
+}
@@ -0,0 +1,177 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	DEFAULT_TX_MAX_RETRIES = 3
+	DEFAULT_TX_RETRY_DELAY = 50 * time.Millisecond
+)
+
+var ErrTxPanic = errors.New("transaction panicked")
+
+type (
+	TxOptions struct {
+		Isolation sql.IsolationLevel
+		ReadOnly  bool
+
+		// Number of times the transaction is re-run after a serialization failure, deadlock or busy database.
+		MaxRetries int
+
+		// Delay before the first retry, doubled on every subsequent attempt.
+		RetryDelay time.Duration
+	}
+
+	// Transaction in progress, as carried by the context handed to the WithTx callback.
+	txState struct {
+		tx    *sql.Tx
+		depth int
+	}
+
+	txContextKey struct{}
+)
+
+// WithTx - runs `fn` inside a transaction, committing if it returns nil and rolling back otherwise.
+//
+// Panics raised by `fn` are recovered, the transaction is rolled back and ErrTxPanic is returned.
+// Serialization failures and deadlocks (Postgres) or a busy database (SQLite) cause the
+// whole transaction to be re-run up to `opts.MaxRetries` times. A nil `opts` retries
+// DEFAULT_TX_MAX_RETRIES times.
+//
+// The context handed to `fn` carries the transaction. Calling WithTx again with that context
+// creates a savepoint instead of a new transaction, so a failing inner call only undoes its own work.
+//
+// Usage:
+//
+//	err := WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+//		if _, err := tx.ExecContext(ctx, `INSERT INTO users (name) VALUES ($1)`, "alice"); err != nil {
+//			return err
+//		}
+//
+//		return WithTx(ctx, db, nil, func(ctx context.Context, tx *sql.Tx) error {
+//			_, err := tx.ExecContext(ctx, `INSERT INTO audits (action) VALUES ($1)`, "signup")
+//			return err
+//		})
+//	})
+func WithTx(ctx context.Context, backend SqlBackend, opts *TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if state, ok := ctx.Value(txContextKey{}).(txState); ok {
+		return withSavepoint(ctx, state, fn)
+	}
+
+	if opts == nil {
+		opts = &TxOptions{MaxRetries: DEFAULT_TX_MAX_RETRIES}
+	}
+
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = DEFAULT_TX_RETRY_DELAY
+	}
+
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		err = runTx(ctx, backend, opts, fn)
+		if err == nil || attempt >= opts.MaxRetries || !IsRetryableError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay << attempt):
+		}
+	}
+}
+
+// TxFromContext - returns the transaction carried by a context handed to a WithTx callback.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	state, ok := ctx.Value(txContextKey{}).(txState)
+	return state.tx, ok
+}
+
+// IsRetryableError - returns true if the error indicates the transaction may succeed if re-run.
+//
+// It matches Postgres serialization failures (40001) and deadlocks (40P01), as well as
+// SQLite's SQLITE_BUSY and SQLITE_LOCKED errors.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+
+	message := err.Error()
+
+	for _, pattern := range []string{"database is locked", "database table is locked", "SQLITE_BUSY", "SQLITE_LOCKED"} {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func runTx(ctx context.Context, backend SqlBackend, opts *TxOptions, fn func(context.Context, *sql.Tx) error) (err error) {
+	tx, err := backend.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrTxPanic, r)
+		}
+
+		if err != nil {
+			if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+				err = errors.Join(err, rerr)
+			}
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txContextKey{}, txState{tx: tx}), tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func withSavepoint(ctx context.Context, state txState, fn func(context.Context, *sql.Tx) error) (err error) {
+	state.depth++
+	savepoint := fmt.Sprintf("sp_%d", state.depth)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrTxPanic, r)
+		}
+
+		if err != nil {
+			if _, rerr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txContextKey{}, state), state.tx); err != nil {
+		return err
+	}
+
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+
+	return err
+}
@@ -0,0 +1,74 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oleoneto/go-toolkit/sqldb/sqltest"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "postgres serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "postgres deadlock", err: fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "40P01"}), want: true},
+		{name: "postgres unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "sqlite busy", err: errors.New("database is locked"), want: true},
+		{name: "sqlite locked table", err: errors.New("database table is locked: users"), want: true},
+		{name: "other", err: errors.New("no such table: users"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTx_retries(t *testing.T) {
+	db := sqltest.New(t)
+
+	db.ExpectExec(`^INSERT INTO users`).WillReturnError(errors.New("database is locked"))
+	db.ExpectExec(`^INSERT INTO users`).WillReturnResult(1, 1)
+	db.ExpectExec(`^SAVEPOINT sp_1$`)
+	db.ExpectExec(`^RELEASE SAVEPOINT sp_1$`)
+
+	opts := &TxOptions{MaxRetries: 1, RetryDelay: time.Millisecond}
+
+	err := WithTx(context.Background(), db, opts, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO users (name) VALUES (?)`, "alice"); err != nil {
+			return err
+		}
+
+		return WithTx(ctx, db, nil, func(context.Context, *sql.Tx) error { return nil })
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+
+	kinds := []sqltest.CallKind{}
+	for _, call := range db.Calls() {
+		kinds = append(kinds, call.Kind)
+	}
+
+	want := []sqltest.CallKind{
+		sqltest.BEGIN, sqltest.EXEC, sqltest.ROLLBACK,
+		sqltest.BEGIN, sqltest.EXEC, sqltest.EXEC, sqltest.EXEC, sqltest.COMMIT,
+	}
+
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("WithTx() calls = %v, want %v", kinds, want)
+	}
+}